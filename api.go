@@ -0,0 +1,70 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// DEFAULT_API_BASE_URL is the default base URL for the GitHub API.
+const DEFAULT_API_BASE_URL string = "https://api.github.com/"
+
+// tokenTransport implements the `http.RoundTripper` interface adding an access token to each request.
+type tokenTransport struct {
+	token string
+}
+
+// RoundTrip() executes a single HTTP transaction, adding an `Authorization` header containing the access
+// token used to create 't'.
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	req2 := req.Clone(req.Context())
+	req2.Header.Set("Authorization", fmt.Sprintf("token %s", t.token))
+
+	return http.DefaultTransport.RoundTrip(req2)
+}
+
+// newAPIClient returns a new `gogithub.Client` instance for talking to the GitHub API at 'base_url'
+// (or `DEFAULT_API_BASE_URL` if empty) using 'token' (if present) for authentication.
+func newAPIClient(base_url string, token string) (*gogithub.Client, error) {
+
+	if base_url == "" {
+		base_url = DEFAULT_API_BASE_URL
+	}
+
+	if !strings.HasSuffix(base_url, "/") {
+		base_url = base_url + "/"
+	}
+
+	u, err := url.Parse(base_url)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse API base URL, %w", err)
+	}
+
+	http_client := &http.Client{}
+
+	if token != "" {
+		http_client.Transport = &tokenTransport{token: token}
+	}
+
+	client := gogithub.NewClient(http_client)
+	client.BaseURL = u
+
+	return client, nil
+}
+
+// splitFullName returns the owner and repository name components of 'full_name' (for example "sfomuseum-data/sfomuseum-data-flights-2020-05").
+func splitFullName(full_name string) (string, string, error) {
+
+	parts := strings.SplitN(full_name, "/", 2)
+
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("Invalid repository name '%s'", full_name)
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,242 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// AUDIT_SCHEMA is the name of the schema used to encode `branch_protection_rule` and `deploy_key` events as JSON.
+const AUDIT_SCHEMA string = "audit"
+
+// AUDIT_SCHEMA_VERSION is the current version of the `AUDIT_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const AUDIT_SCHEMA_VERSION int = 1
+
+// AuditChange is the before and after value of a single property changed by a GitHub `branch_protection_rule` or `deploy_key` event.
+type AuditChange struct {
+	// Field is the name of the property that changed, for example "required_status_checks". When an entire branch protection
+	// rule or deploy key is created or deleted it is "rule" or "key" respectively.
+	Field string `json:"field"`
+	// Before is the (compacted) JSON-encoded value of the property before the change. It is empty when a rule or key is created.
+	Before json.RawMessage `json:"before,omitempty"`
+	// After is the (compacted) JSON-encoded value of the property after the change. It is empty when a rule or key is deleted.
+	After json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditRecord is the JSON-encoded representation of a GitHub `branch_protection_rule` or `deploy_key` event produced by
+// transformations in this package.
+type AuditRecord struct {
+	// Schema is the name of the schema for the record. It is always `AUDIT_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event: "branch_protection_rule" or "deploy_key".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event: "created", "edited" or "deleted".
+	Action string `json:"action"`
+	// Repo is the name of the repository whose settings changed.
+	Repo string `json:"repo"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository whose settings changed.
+	FullName string `json:"full_name"`
+	// Target is the name of the branch protection rule (the branch name pattern) or the title of the deploy key.
+	Target string `json:"target"`
+	// TargetID is the unique ID of the branch protection rule or deploy key.
+	TargetID int64 `json:"target_id"`
+	// Actor is the login of the user who changed the settings.
+	Actor string `json:"actor"`
+	// Changes is the list of changes, sorted by field name.
+	Changes []*AuditChange `json:"changes"`
+}
+
+// auditChanges contains the (raw) `rule`, `key` and `changes` fields of `branch_protection_rule` and `deploy_key` webhook messages.
+// The `changes` field is decoded generically, rather than using the go-github `ProtectionChanges` type, so that properties added
+// by GitHub after the vendored version of go-github are still recorded.
+type auditChanges struct {
+	Rule    map[string]json.RawMessage `json:"rule"`
+	Key     json.RawMessage            `json:"key"`
+	Changes map[string]struct {
+		From json.RawMessage `json:"from"`
+	} `json:"changes"`
+}
+
+// unmarshalAuditChanges returns the `auditChanges` for 'body'.
+func unmarshalAuditChanges(body []byte) (*auditChanges, error) {
+
+	var changes auditChanges
+
+	err := json.Unmarshal(body, &changes)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal changes, %w", err)
+	}
+
+	return &changes, nil
+}
+
+// newAuditChange returns a new `AuditChange` for 'field' derived from 'action' and 'value'. Created objects are
+// recorded as the after value and deleted objects as the before value.
+func newAuditChange(field string, action string, value json.RawMessage) *AuditChange {
+
+	c := &AuditChange{
+		Field: field,
+	}
+
+	switch action {
+	case "deleted":
+		c.Before = compactPayload(value)
+	default:
+		c.After = compactPayload(value)
+	}
+
+	return c
+}
+
+// newAuditRecord returns a new `AuditRecord` instance for the event 'event_type' derived from 'action', 'repo' and 'sender'.
+func newAuditRecord(event_type string, action string, repo *gogithub.Repository, sender *gogithub.User) *AuditRecord {
+
+	rec := &AuditRecord{
+		Schema:   AUDIT_SCHEMA,
+		Version:  AUDIT_SCHEMA_VERSION,
+		Event:    event_type,
+		Action:   action,
+		Repo:     repo.GetName(),
+		FullName: repo.GetFullName(),
+		Actor:    sender.GetLogin(),
+		Changes:  make([]*AuditChange, 0),
+	}
+
+	return rec
+}
+
+// newAuditRecordFromBranchProtectionRule returns a new `AuditRecord` instance derived from 'event' and 'body', the webhook
+// message 'event' was unmarshaled from. "edited" actions have one change for each property in the `changes` field, whose
+// after value is the current value of that property in the rule. "created" and "deleted" actions have a single "rule" change.
+func newAuditRecordFromBranchProtectionRule(event *gogithub.BranchProtectionRuleEvent, body []byte) (*AuditRecord, error) {
+
+	changes, err := unmarshalAuditChanges(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rule := event.GetRule()
+
+	rec := newAuditRecord("branch_protection_rule", event.GetAction(), event.GetRepo(), event.GetSender())
+	rec.Target = rule.GetName()
+	rec.TargetID = rule.GetID()
+
+	if rec.Action != "edited" {
+
+		enc_rule, err := json.Marshal(changes.Rule)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to marshal rule, %w", err)
+		}
+
+		rec.Changes = append(rec.Changes, newAuditChange("rule", rec.Action, enc_rule))
+		return rec, nil
+	}
+
+	for field, c := range changes.Changes {
+
+		change := &AuditChange{
+			Field:  field,
+			Before: compactPayload(c.From),
+			After:  compactPayload(changes.Rule[field]),
+		}
+
+		rec.Changes = append(rec.Changes, change)
+	}
+
+	sort.Slice(rec.Changes, func(i, j int) bool {
+		return rec.Changes[i].Field < rec.Changes[j].Field
+	})
+
+	return rec, nil
+}
+
+// newAuditRecordFromDeployKey returns a new `AuditRecord` instance derived from 'event' and 'body', the webhook message 'event'
+// was unmarshaled from. Deploy keys can not be edited so every record has a single "key" change.
+func newAuditRecordFromDeployKey(event *gogithub.DeployKeyEvent, body []byte) (*AuditRecord, error) {
+
+	changes, err := unmarshalAuditChanges(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := event.GetKey()
+
+	rec := newAuditRecord("deploy_key", event.GetAction(), event.GetRepo(), event.GetSender())
+	rec.Target = key.GetTitle()
+	rec.TargetID = key.GetID()
+	rec.Changes = append(rec.Changes, newAuditChange("key", rec.Action, changes.Key))
+
+	return rec, nil
+}
+
+// filterAuditChanges returns the subset of 'changes' whose fields match any of the `path.Match` patterns in 'fields'
+// (or all changes if 'fields' is empty).
+func filterAuditChanges(changes []*AuditChange, fields []string) []*AuditChange {
+
+	filtered := make([]*AuditChange, 0)
+
+	for _, c := range changes {
+
+		if matchesPattern(c.Field, fields) {
+			filtered = append(filtered, c)
+		}
+	}
+
+	return filtered
+}
+
+// auditRuleFieldNames is the list of field names that rules for audit events may be evaluated against.
+var auditRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"target",
+	"actor",
+	"field",
+}
+
+// auditRuleFields returns the `RuleFields` for 'rec' used to evaluate rules. The "field" field contains one value for each change.
+func auditRuleFields(rec *AuditRecord) RuleFields {
+
+	fields := make([]string, len(rec.Changes))
+
+	for idx, c := range rec.Changes {
+		fields[idx] = c.Field
+	}
+
+	rule_fields := RuleFields{
+		"event":     []string{rec.Event},
+		"action":    []string{rec.Action},
+		"repo":      []string{rec.Repo},
+		"full_name": []string{rec.FullName},
+		"target":    []string{rec.Target},
+		"actor":     []string{rec.Actor},
+		"field":     fields,
+	}
+
+	return rule_fields
+}
+
+// auditRows returns the CSV rows for 'rec', one for each change: the name of the repository, the name of the event, the action,
+// the target, the field, the previous value, the current value and the actor.
+func auditRows(rec *AuditRecord) [][]string {
+
+	rows := make([][]string, 0)
+
+	for _, c := range rec.Changes {
+		row := []string{rec.Repo, rec.Event, rec.Action, rec.Target, c.Field, string(c.Before), string(c.After), rec.Actor}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
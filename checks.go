@@ -0,0 +1,240 @@
+package github
+
+import (
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// CHECK_SCHEMA is the name of the schema used to encode `status`, `check_suite` and `check_run` events as JSON.
+const CHECK_SCHEMA string = "check"
+
+// CHECK_SCHEMA_VERSION is the current version of the `CHECK_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const CHECK_SCHEMA_VERSION int = 1
+
+// RESULT_GREEN is the normalized result for commit statuses and checks which succeeded.
+const RESULT_GREEN string = "green"
+
+// RESULT_RED is the normalized result for commit statuses and checks which failed, errored, timed out, were cancelled or require action.
+const RESULT_RED string = "red"
+
+// RESULT_PENDING is the normalized result for commit statuses and checks which have not completed.
+const RESULT_PENDING string = "pending"
+
+// RESULT_NEUTRAL is the normalized result for checks which completed without succeeding or failing, for example
+// because they were skipped.
+const RESULT_NEUTRAL string = "neutral"
+
+// CheckRecord is the JSON-encoded representation of a GitHub `status`, `check_suite` or `check_run` event produced by
+// transformations in this package. It describes the (normalized) result of a single context for a single commit.
+type CheckRecord struct {
+	// Schema is the name of the schema for the record. It is always `CHECK_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event: "status", "check_suite" or "check_run".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event, for example "completed". It is empty for `status` events.
+	Action string `json:"action,omitempty"`
+	// Repo is the name of the repository containing the commit.
+	Repo string `json:"repo"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository containing the commit.
+	FullName string `json:"full_name"`
+	// SHA is the hash of the commit.
+	SHA string `json:"sha"`
+	// Context is the name of the status context, check run or (the app that created the) check suite.
+	Context string `json:"context"`
+	// State is the state of a commit status, or the conclusion of a completed check, or the status of a check which has not completed.
+	State string `json:"state"`
+	// Result is the normalized result for the commit and context: `RESULT_GREEN`, `RESULT_RED`, `RESULT_PENDING` or `RESULT_NEUTRAL`.
+	Result string `json:"result"`
+	// Description is the description of a commit status or the title of a check run's output.
+	Description string `json:"description,omitempty"`
+	// Branches is the list of branches the commit belongs to.
+	Branches []string `json:"branches"`
+	// URL is the URL with details about the commit status or check.
+	URL string `json:"url,omitempty"`
+}
+
+// statusResult returns the normalized result for the commit status state 'state'.
+func statusResult(state string) string {
+
+	switch state {
+	case "success":
+		return RESULT_GREEN
+	case "failure", "error":
+		return RESULT_RED
+	default:
+		return RESULT_PENDING
+	}
+}
+
+// checkResult returns the normalized result for a check with status 'status' and conclusion 'conclusion'.
+func checkResult(status string, conclusion string) string {
+
+	if status != "completed" {
+		return RESULT_PENDING
+	}
+
+	switch conclusion {
+	case "success":
+		return RESULT_GREEN
+	case "failure", "timed_out", "cancelled", "action_required", "startup_failure":
+		return RESULT_RED
+	default:
+		return RESULT_NEUTRAL
+	}
+}
+
+// checkState returns the conclusion of a check with status 'status' and conclusion 'conclusion' if it has completed
+// or its status if it has not.
+func checkState(status string, conclusion string) string {
+
+	if status != "completed" || conclusion == "" {
+		return status
+	}
+
+	return conclusion
+}
+
+// newCheckRecordFromStatus returns a new `CheckRecord` instance derived from 'event'.
+func newCheckRecordFromStatus(event *gogithub.StatusEvent) *CheckRecord {
+
+	repo := event.GetRepo()
+
+	rec := &CheckRecord{
+		Schema:      CHECK_SCHEMA,
+		Version:     CHECK_SCHEMA_VERSION,
+		Event:       "status",
+		Repo:        repo.GetName(),
+		FullName:    repo.GetFullName(),
+		SHA:         event.GetSHA(),
+		Context:     event.GetContext(),
+		State:       event.GetState(),
+		Result:      statusResult(event.GetState()),
+		Description: event.GetDescription(),
+		Branches:    make([]string, len(event.Branches)),
+		URL:         event.GetTargetURL(),
+	}
+
+	for idx, b := range event.Branches {
+		rec.Branches[idx] = b.GetName()
+	}
+
+	return rec
+}
+
+// newCheckRecordFromSuite returns a new `CheckRecord` instance derived from 'event'. The context for check suites
+// is the name of the app which created the suite.
+func newCheckRecordFromSuite(event *gogithub.CheckSuiteEvent) *CheckRecord {
+
+	suite := event.GetCheckSuite()
+	repo := event.GetRepo()
+
+	rec := &CheckRecord{
+		Schema:   CHECK_SCHEMA,
+		Version:  CHECK_SCHEMA_VERSION,
+		Event:    "check_suite",
+		Action:   event.GetAction(),
+		Repo:     repo.GetName(),
+		FullName: repo.GetFullName(),
+		SHA:      suite.GetHeadSHA(),
+		Context:  suite.GetApp().GetName(),
+		State:    checkState(suite.GetStatus(), suite.GetConclusion()),
+		Result:   checkResult(suite.GetStatus(), suite.GetConclusion()),
+		Branches: make([]string, 0),
+		URL:      suite.GetURL(),
+	}
+
+	if suite.GetHeadBranch() != "" {
+		rec.Branches = append(rec.Branches, suite.GetHeadBranch())
+	}
+
+	return rec
+}
+
+// newCheckRecordFromRun returns a new `CheckRecord` instance derived from 'event'.
+func newCheckRecordFromRun(event *gogithub.CheckRunEvent) *CheckRecord {
+
+	run := event.GetCheckRun()
+	repo := event.GetRepo()
+
+	rec := &CheckRecord{
+		Schema:      CHECK_SCHEMA,
+		Version:     CHECK_SCHEMA_VERSION,
+		Event:       "check_run",
+		Action:      event.GetAction(),
+		Repo:        repo.GetName(),
+		FullName:    repo.GetFullName(),
+		SHA:         run.GetHeadSHA(),
+		Context:     run.GetName(),
+		State:       checkState(run.GetStatus(), run.GetConclusion()),
+		Result:      checkResult(run.GetStatus(), run.GetConclusion()),
+		Description: run.GetOutput().GetTitle(),
+		Branches:    make([]string, 0),
+		URL:         run.GetHTMLURL(),
+	}
+
+	if run.GetCheckSuite().GetHeadBranch() != "" {
+		rec.Branches = append(rec.Branches, run.GetCheckSuite().GetHeadBranch())
+	}
+
+	return rec
+}
+
+// checkRuleFieldNames is the list of field names that rules for commit status and check events may be evaluated against.
+var checkRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"sha",
+	"context",
+	"state",
+	"result",
+	"description",
+	"branch",
+}
+
+// checkRuleFields returns the `RuleFields` for 'rec' used to evaluate rules.
+func checkRuleFields(rec *CheckRecord) RuleFields {
+
+	fields := RuleFields{
+		"event":       []string{rec.Event},
+		"action":      []string{rec.Action},
+		"repo":        []string{rec.Repo},
+		"full_name":   []string{rec.FullName},
+		"sha":         []string{rec.SHA},
+		"context":     []string{rec.Context},
+		"state":       []string{rec.State},
+		"result":      []string{rec.Result},
+		"description": []string{rec.Description},
+		"branch":      rec.Branches,
+	}
+
+	return fields
+}
+
+// matchesAnyBranch returns true if any of 'branches' matches any of the `path.Match` patterns in 'patterns' or if 'patterns' is empty.
+func matchesAnyBranch(branches []string, patterns []string) bool {
+
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, b := range branches {
+
+		if matchesPattern(b, patterns) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkRows returns the CSV rows for 'rec': the name of the repository, the commit hash, the context, the normalized result,
+// the state (or conclusion) and the details URL.
+func checkRows(rec *CheckRecord) [][]string {
+
+	row := []string{rec.Repo, rec.SHA, rec.Context, rec.Result, rec.State, rec.URL}
+	return [][]string{row}
+}
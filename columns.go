@@ -0,0 +1,192 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// COLUMN_STATUS is the name of the CSV column containing the single-letter status code (A, M, D, R) of a change.
+const COLUMN_STATUS string = "status"
+
+// COLUMN_ACTION is the name of the CSV column containing the action (added, modified, removed, renamed) of a change.
+const COLUMN_ACTION string = "action"
+
+// COLUMN_COMMIT is the name of the CSV column containing the hash of the head commit of a push.
+const COLUMN_COMMIT string = "commit"
+
+// COLUMN_CHANGE_COMMIT is the name of the CSV column containing the hash of the commit in which a change occurred.
+const COLUMN_CHANGE_COMMIT string = "change_commit"
+
+// COLUMN_REPO is the name of the CSV column containing the name of the repository where a change occurred.
+const COLUMN_REPO string = "repo"
+
+// COLUMN_PATH is the name of the CSV column containing the path of the file that was changed.
+const COLUMN_PATH string = "path"
+
+// COLUMN_PREVIOUS_PATH is the name of the CSV column containing the path of a file before it was renamed.
+const COLUMN_PREVIOUS_PATH string = "previous_path"
+
+// COLUMN_RAW_URL is the name of the CSV column containing the URL for the raw contents of the file that was changed.
+const COLUMN_RAW_URL string = "raw_url"
+
+// COLUMN_HTML_URL is the name of the CSV column containing the URL for the (HTML) blob view of the file that was changed.
+const COLUMN_HTML_URL string = "html_url"
+
+// COLUMN_API_URL is the name of the CSV column containing the URL for the GitHub contents API for the file that was changed.
+const COLUMN_API_URL string = "api_url"
+
+// COLUMN_MESSAGE is the name of the CSV column containing the message of the commit in which a change occurred.
+const COLUMN_MESSAGE string = "message"
+
+// COLUMN_AUTHOR is the name of the CSV column containing the author of the commit in which a change occurred.
+const COLUMN_AUTHOR string = "author"
+
+// COLUMN_CO_AUTHORS is the name of the CSV column containing the (semi-colon separated) co-authors of the commit in which a change occurred.
+const COLUMN_CO_AUTHORS string = "co_authors"
+
+// COLUMN_TRAILER_PREFIX is the prefix for CSV columns containing the (semi-colon separated) values of a Git trailer, for
+// example "trailer.Signed-off-by", in the message of the commit in which a change occurred.
+const COLUMN_TRAILER_PREFIX string = "trailer."
+
+// urlColumns is the list of CSV columns appended to the output of commit transformations when URLs are included.
+var urlColumns = []string{
+	COLUMN_RAW_URL,
+	COLUMN_HTML_URL,
+	COLUMN_API_URL,
+}
+
+// defaultColumns is the default list of CSV columns output by commit transformations.
+var defaultColumns = []string{
+	COLUMN_COMMIT,
+	COLUMN_REPO,
+	COLUMN_PATH,
+}
+
+// renameColumns is the list of CSV columns output by commit transformations when rename detection is enabled.
+var renameColumns = []string{
+	COLUMN_STATUS,
+	COLUMN_COMMIT,
+	COLUMN_REPO,
+	COLUMN_PATH,
+	COLUMN_PREVIOUS_PATH,
+}
+
+// parseColumns returns the list of valid CSV column names in 'names'.
+func parseColumns(names []string) ([]string, error) {
+
+	columns := make([]string, 0)
+
+	for _, n := range names {
+
+		switch n {
+		case COLUMN_STATUS, COLUMN_ACTION, COLUMN_COMMIT, COLUMN_CHANGE_COMMIT, COLUMN_REPO, COLUMN_PATH, COLUMN_PREVIOUS_PATH:
+			// pass
+		case COLUMN_RAW_URL, COLUMN_HTML_URL, COLUMN_API_URL:
+			// pass
+		case COLUMN_MESSAGE, COLUMN_AUTHOR, COLUMN_CO_AUTHORS:
+			// pass
+		default:
+
+			if !strings.HasPrefix(n, COLUMN_TRAILER_PREFIX) || n == COLUMN_TRAILER_PREFIX {
+				return nil, fmt.Errorf("Invalid column '%s'", n)
+			}
+		}
+
+		columns = append(columns, n)
+	}
+
+	return columns, nil
+}
+
+// hasURLColumns returns a boolean value indicating whether 'columns' contains any of the URL columns.
+func hasURLColumns(columns []string) bool {
+
+	for _, col := range columns {
+
+		switch col {
+		case COLUMN_RAW_URL, COLUMN_HTML_URL, COLUMN_API_URL:
+			return true
+		}
+	}
+
+	return false
+}
+
+// changeStatus returns the single-letter status code, modeled on the output of `git diff --name-status`, for 'action'.
+func changeStatus(action string) string {
+
+	switch action {
+	case ACTION_ADDED:
+		return "A"
+	case ACTION_MODIFIED:
+		return "M"
+	case ACTION_REMOVED:
+		return "D"
+	case ACTION_RENAMED:
+		return "R"
+	default:
+		return ""
+	}
+}
+
+// changeRow returns the values of 'columns' for 'ch', which is a change that occurred in 'event'.
+func changeRow(event *gogithub.PushEvent, ch *Change, columns []string) []string {
+
+	row := make([]string, len(columns))
+
+	for idx, col := range columns {
+
+		switch col {
+		case COLUMN_STATUS:
+			row[idx] = changeStatus(ch.Action)
+		case COLUMN_ACTION:
+			row[idx] = ch.Action
+		case COLUMN_COMMIT:
+			row[idx] = event.GetHeadCommit().GetID()
+		case COLUMN_CHANGE_COMMIT:
+			row[idx] = ch.Commit
+		case COLUMN_REPO:
+			row[idx] = ch.Repo
+		case COLUMN_PATH:
+			row[idx] = ch.Path
+		case COLUMN_PREVIOUS_PATH:
+			row[idx] = ch.PreviousPath
+		case COLUMN_RAW_URL:
+			if ch.FileURLs != nil {
+				row[idx] = ch.RawURL
+			}
+		case COLUMN_HTML_URL:
+			if ch.FileURLs != nil {
+				row[idx] = ch.HTMLURL
+			}
+		case COLUMN_API_URL:
+			if ch.FileURLs != nil {
+				row[idx] = ch.APIURL
+			}
+		case COLUMN_MESSAGE:
+			row[idx] = ch.commit.GetMessage()
+		case COLUMN_AUTHOR:
+			row[idx] = ch.commit.GetAuthor().GetName()
+		case COLUMN_CO_AUTHORS:
+
+			names := make([]string, 0)
+
+			for _, a := range parseCoAuthors(ch.Trailers) {
+				names = append(names, a.Name)
+			}
+
+			row[idx] = strings.Join(names, ";")
+
+		default:
+
+			if strings.HasPrefix(col, COLUMN_TRAILER_PREFIX) {
+				key := strings.TrimPrefix(col, COLUMN_TRAILER_PREFIX)
+				row[idx] = strings.Join(trailerValues(ch.Trailers, key), ";")
+			}
+		}
+	}
+
+	return row
+}
@@ -0,0 +1,266 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// MAX_PUSH_COMMITS is the maximum number of commits that GitHub will include in a `push` event payload.
+const MAX_PUSH_COMMITS int = 20
+
+// COMPARE_NEVER signals that the GitHub compare API should never be used to enrich `push` events.
+const COMPARE_NEVER string = "never"
+
+// COMPARE_AUTO signals that the GitHub compare API should be used to enrich `push` events that appear to be truncated.
+const COMPARE_AUTO string = "auto"
+
+// COMPARE_ALWAYS signals that the GitHub compare API should always be used to enrich `push` events.
+const COMPARE_ALWAYS string = "always"
+
+// NULL_SHA is the commit hash GitHub uses to indicate the absence of a commit, for example the 'before' hash of a newly created branch.
+const NULL_SHA string = "0000000000000000000000000000000000000000"
+
+// MAX_COMPARE_FILES is the maximum number of files that the GitHub compare API will include in a comparison.
+const MAX_COMPARE_FILES int = 300
+
+// parseCompareMode returns a valid compare mode derived from 's'. The empty string and "false" are mapped to
+// `COMPARE_NEVER` and "true" is mapped to `COMPARE_AUTO`.
+func parseCompareMode(s string) (string, error) {
+
+	switch strings.ToLower(s) {
+	case "", "false", COMPARE_NEVER:
+		return COMPARE_NEVER, nil
+	case "true", COMPARE_AUTO:
+		return COMPARE_AUTO, nil
+	case COMPARE_ALWAYS:
+		return COMPARE_ALWAYS, nil
+	default:
+		return "", fmt.Errorf("Invalid compare mode '%s'", s)
+	}
+}
+
+// isTruncatedPush returns a boolean value indicating whether the list of commits in 'event' appears to have been
+// truncated by GitHub, either because it contains `MAX_PUSH_COMMITS` commits or because the `size` or `distinct_size`
+// properties report more commits than are included in the payload.
+func isTruncatedPush(event *gogithub.PushEvent) bool {
+
+	count := len(event.Commits)
+
+	if count >= MAX_PUSH_COMMITS {
+		return true
+	}
+
+	if event.GetSize() > count {
+		return true
+	}
+
+	distinct := 0
+
+	for _, c := range event.Commits {
+		if c.GetDistinct() {
+			distinct += 1
+		}
+	}
+
+	if event.GetDistinctSize() > distinct {
+		return true
+	}
+
+	return false
+}
+
+// canComparePush returns a boolean value indicating whether the commits in 'event' can be compared using the GitHub compare API.
+// Pushes that create or delete a branch can not be compared.
+func canComparePush(event *gogithub.PushEvent) bool {
+
+	before := event.GetBefore()
+	after := event.GetAfter()
+
+	if before == "" || before == NULL_SHA {
+		return false
+	}
+
+	if after == "" || after == NULL_SHA {
+		return false
+	}
+
+	return true
+}
+
+// compareCommits returns the list of files that changed between the 'base' and 'head' commits in the repository
+// 'owner'/'repo' and the complete list of commits between them. The compare API paginates commits but not files, which
+// are only included (up to `MAX_COMPARE_FILES`) in the first page of results.
+func compareCommits(ctx context.Context, client *gogithub.Client, owner string, repo string, base string, head string) ([]*gogithub.CommitFile, []*gogithub.RepositoryCommit, error) {
+
+	var files []*gogithub.CommitFile
+	commits := make([]*gogithub.RepositoryCommit, 0)
+
+	opts := &gogithub.ListOptions{
+		PerPage: 100,
+	}
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		default:
+			// pass
+		}
+
+		cmp, rsp, err := client.Repositories.CompareCommits(ctx, owner, repo, base, head, opts)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to compare %s...%s, %w", base, head, err)
+		}
+
+		if files == nil {
+			files = cmp.Files
+		}
+
+		commits = append(commits, cmp.Commits...)
+
+		if rsp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = rsp.NextPage
+	}
+
+	return files, commits, nil
+}
+
+// commitFiles returns the complete list of files changed by the commit 'sha' in the repository 'owner'/'repo', paging
+// through results as necessary.
+func commitFiles(ctx context.Context, client *gogithub.Client, owner string, repo string, sha string) ([]*gogithub.CommitFile, error) {
+
+	files := make([]*gogithub.CommitFile, 0)
+
+	opts := &gogithub.ListOptions{
+		PerPage: 100,
+	}
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			// pass
+		}
+
+		c, rsp, err := client.Repositories.GetCommit(ctx, owner, repo, sha, opts)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to retrieve commit %s, %w", sha, err)
+		}
+
+		files = append(files, c.Files...)
+
+		if rsp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = rsp.NextPage
+	}
+
+	return files, nil
+}
+
+// compareChanges returns the list of file changes for 'event' derived from the GitHub compare API, using 'client', for
+// the 'before' and 'after' commits in 'event'. If 'detect_renames' is true then renamed files are reported as a single change
+// whose action is `renamed`, otherwise they are reported as the removal of the previous path followed by the addition of the new path.
+// If the comparison contains `MAX_COMPARE_FILES` files (meaning the list of files may have been truncated) then changes are derived
+// from each of the commits between 'before' and 'after' instead and attributed to the individual commits.
+func compareChanges(ctx context.Context, client *gogithub.Client, event *gogithub.PushEvent, detect_renames bool) ([]*Change, error) {
+
+	repo_name := event.GetRepo().GetName()
+
+	owner, repo, err := splitFullName(event.GetRepo().GetFullName())
+
+	if err != nil {
+		return nil, err
+	}
+
+	commit_hash := event.GetAfter()
+
+	var commit *gogithub.HeadCommit
+	var trailers []*Trailer
+
+	if event.GetHeadCommit().GetID() == commit_hash {
+		commit = event.GetHeadCommit()
+		trailers = parseTrailers(commit.GetMessage())
+	}
+
+	files, commits, err := compareCommits(ctx, client, owner, repo, event.GetBefore(), commit_hash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) < MAX_COMPARE_FILES {
+		return fileChanges(files, repo_name, commit_hash, commit, trailers, detect_renames), nil
+	}
+
+	changes := make([]*Change, 0)
+
+	for _, c := range commits {
+
+		sha := c.GetSHA()
+
+		c_files, err := commitFiles(ctx, client, owner, repo, sha)
+
+		if err != nil {
+			return nil, err
+		}
+
+		head := &gogithub.HeadCommit{
+			ID:      gogithub.String(sha),
+			Message: gogithub.String(c.GetCommit().GetMessage()),
+			Author:  c.GetCommit().Author,
+		}
+
+		c_trailers := parseTrailers(head.GetMessage())
+		c_changes := fileChanges(c_files, repo_name, sha, head, c_trailers, detect_renames)
+
+		changes = append(changes, c_changes...)
+	}
+
+	return changes, nil
+}
+
+// fileChanges returns the list of file changes for 'files' attributed to the commit 'commit_hash' in the repository 'repo_name'.
+func fileChanges(files []*gogithub.CommitFile, repo_name string, commit_hash string, commit *gogithub.HeadCommit, trailers []*Trailer, detect_renames bool) []*Change {
+
+	changes := make([]*Change, 0)
+
+	for _, f := range files {
+
+		path := f.GetFilename()
+
+		switch f.GetStatus() {
+		case "added", "copied":
+			changes = append(changes, &Change{Action: ACTION_ADDED, Commit: commit_hash, Repo: repo_name, Path: path, Trailers: trailers, commit: commit})
+		case "removed":
+			changes = append(changes, &Change{Action: ACTION_REMOVED, Commit: commit_hash, Repo: repo_name, Path: path, Trailers: trailers, commit: commit})
+		case "renamed":
+
+			if detect_renames {
+				changes = append(changes, &Change{Action: ACTION_RENAMED, Commit: commit_hash, Repo: repo_name, Path: path, PreviousPath: f.GetPreviousFilename(), Trailers: trailers, commit: commit})
+				continue
+			}
+
+			changes = append(changes, &Change{Action: ACTION_REMOVED, Commit: commit_hash, Repo: repo_name, Path: f.GetPreviousFilename(), Trailers: trailers, commit: commit})
+			changes = append(changes, &Change{Action: ACTION_ADDED, Commit: commit_hash, Repo: repo_name, Path: path, Trailers: trailers, commit: commit})
+		case "unchanged":
+			// pass
+		default:
+			changes = append(changes, &Change{Action: ACTION_MODIFIED, Commit: commit_hash, Repo: repo_name, Path: path, Trailers: trailers, commit: commit})
+		}
+	}
+
+	return changes
+}
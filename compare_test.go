@@ -0,0 +1,47 @@
+package github
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+func TestIsTruncatedPush(t *testing.T) {
+
+	tests := map[string]bool{
+		"fixtures/events/flights.json":   false,
+		"fixtures/events/truncated.json": true,
+	}
+
+	for msg, expected := range tests {
+
+		fh, err := os.Open(msg)
+
+		if err != nil {
+			t.Fatalf("Failed to open %s, %v", msg, err)
+		}
+
+		defer fh.Close()
+
+		body, err := io.ReadAll(fh)
+
+		if err != nil {
+			t.Fatalf("Failed to read %s, %v", msg, err)
+		}
+
+		var event gogithub.PushEvent
+
+		err = json.Unmarshal(body, &event)
+
+		if err != nil {
+			t.Fatalf("Failed to unmarshal %s, %v", msg, err)
+		}
+
+		if isTruncatedPush(&event) != expected {
+			t.Fatalf("Expected truncated to be %t for %s", expected, msg)
+		}
+	}
+}
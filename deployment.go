@@ -0,0 +1,168 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// DEPLOYMENT_SCHEMA is the name of the schema used to encode `deployment` and `deployment_status` events as JSON.
+const DEPLOYMENT_SCHEMA string = "deployment"
+
+// DEPLOYMENT_SCHEMA_VERSION is the current version of the `DEPLOYMENT_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const DEPLOYMENT_SCHEMA_VERSION int = 1
+
+// DeploymentRecord is the JSON-encoded representation of a GitHub `deployment` or `deployment_status` event produced by transformations in this package.
+type DeploymentRecord struct {
+	// Schema is the name of the schema for the record. It is always `DEPLOYMENT_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event: "deployment" or "deployment_status".
+	Event string `json:"event"`
+	// Repo is the name of the repository being deployed.
+	Repo string `json:"repo"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository being deployed.
+	FullName string `json:"full_name"`
+	// ID is the unique ID of the deployment.
+	ID int64 `json:"id"`
+	// Environment is the name of the environment being deployed to, for example "production".
+	Environment string `json:"environment"`
+	// Ref is the name of the ref (branch, tag or commit hash) being deployed.
+	Ref string `json:"ref"`
+	// SHA is the hash of the commit being deployed.
+	SHA string `json:"sha"`
+	// Task is the name of the task to execute, for example "deploy".
+	Task string `json:"task,omitempty"`
+	// Description is the description of the deployment, or of the deployment status for `deployment_status` events.
+	Description string `json:"description,omitempty"`
+	// Creator is the login of the user who created the deployment.
+	Creator string `json:"creator"`
+	// State is the state of the deployment status, for example "in_progress", "success" or "failure". It is empty for `deployment` events.
+	State string `json:"state,omitempty"`
+	// Payload is the (compacted) free-form JSON payload of the deployment.
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// URL is the GitHub API URL for the deployment.
+	URL string `json:"url"`
+	// StatusesURL is the GitHub API URL for the list of statuses for the deployment.
+	StatusesURL string `json:"statuses_url"`
+	// TargetURL is the URL with details about the deployment status. It is empty for `deployment` events.
+	TargetURL string `json:"target_url,omitempty"`
+	// LogURL is the URL of the logs for the deployment status. It is empty for `deployment` events.
+	LogURL string `json:"log_url,omitempty"`
+	// EnvironmentURL is the URL of the deployed environment. It is empty for `deployment` events.
+	EnvironmentURL string `json:"environment_url,omitempty"`
+}
+
+// compactPayload returns the compacted representation of 'payload' or 'payload' itself if it can not be compacted.
+// Empty payloads (and the JSON value null) return nil.
+func compactPayload(payload json.RawMessage) json.RawMessage {
+
+	if len(payload) == 0 || string(payload) == "null" {
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+
+	err := json.Compact(buf, payload)
+
+	if err != nil {
+		return payload
+	}
+
+	return json.RawMessage(buf.Bytes())
+}
+
+// newDeploymentRecord returns a new `DeploymentRecord` instance for the event 'event_type' derived from 'deployment' and 'repo'.
+func newDeploymentRecord(event_type string, deployment *gogithub.Deployment, repo *gogithub.Repository) *DeploymentRecord {
+
+	rec := &DeploymentRecord{
+		Schema:      DEPLOYMENT_SCHEMA,
+		Version:     DEPLOYMENT_SCHEMA_VERSION,
+		Event:       event_type,
+		Repo:        repo.GetName(),
+		FullName:    repo.GetFullName(),
+		ID:          deployment.GetID(),
+		Environment: deployment.GetEnvironment(),
+		Ref:         deployment.GetRef(),
+		SHA:         deployment.GetSHA(),
+		Task:        deployment.GetTask(),
+		Description: deployment.GetDescription(),
+		Creator:     deployment.GetCreator().GetLogin(),
+		Payload:     compactPayload(deployment.Payload),
+		URL:         deployment.GetURL(),
+		StatusesURL: deployment.GetStatusesURL(),
+	}
+
+	return rec
+}
+
+// newDeploymentRecordFromDeployment returns a new `DeploymentRecord` instance derived from 'event'.
+func newDeploymentRecordFromDeployment(event *gogithub.DeploymentEvent) *DeploymentRecord {
+	return newDeploymentRecord("deployment", event.GetDeployment(), event.GetRepo())
+}
+
+// newDeploymentRecordFromStatus returns a new `DeploymentRecord` instance derived from 'event'.
+func newDeploymentRecordFromStatus(event *gogithub.DeploymentStatusEvent) *DeploymentRecord {
+
+	status := event.GetDeploymentStatus()
+
+	rec := newDeploymentRecord("deployment_status", event.GetDeployment(), event.GetRepo())
+	rec.State = status.GetState()
+	rec.TargetURL = status.GetTargetURL()
+	rec.LogURL = status.GetLogURL()
+	rec.EnvironmentURL = status.GetEnvironmentURL()
+
+	if status.GetDescription() != "" {
+		rec.Description = status.GetDescription()
+	}
+
+	if status.GetEnvironment() != "" {
+		rec.Environment = status.GetEnvironment()
+	}
+
+	return rec
+}
+
+// deploymentRuleFieldNames is the list of field names that rules for deployment events may be evaluated against.
+var deploymentRuleFieldNames = []string{
+	"event",
+	"repo",
+	"full_name",
+	"environment",
+	"ref",
+	"sha",
+	"task",
+	"description",
+	"creator",
+	"state",
+}
+
+// deploymentRuleFields returns the `RuleFields` for 'rec' used to evaluate rules.
+func deploymentRuleFields(rec *DeploymentRecord) RuleFields {
+
+	fields := RuleFields{
+		"event":       []string{rec.Event},
+		"repo":        []string{rec.Repo},
+		"full_name":   []string{rec.FullName},
+		"environment": []string{rec.Environment},
+		"ref":         []string{rec.Ref},
+		"sha":         []string{rec.SHA},
+		"task":        []string{rec.Task},
+		"description": []string{rec.Description},
+		"creator":     []string{rec.Creator},
+		"state":       []string{rec.State},
+	}
+
+	return fields
+}
+
+// deploymentRows returns the CSV rows for 'rec': the name of the repository, the environment, the ref, the commit hash,
+// the state, the creator, the statuses URL and the (JSON-encoded) deployment payload.
+func deploymentRows(rec *DeploymentRecord) [][]string {
+
+	row := []string{rec.Repo, rec.Environment, rec.Ref, rec.SHA, rec.State, rec.Creator, rec.StatusesURL, string(rec.Payload)}
+	return [][]string{row}
+}
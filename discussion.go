@@ -0,0 +1,230 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// DISCUSSION_SCHEMA is the name of the schema used to encode `discussion`, `discussion_comment` and `commit_comment` events as JSON.
+const DISCUSSION_SCHEMA string = "discussion"
+
+// DISCUSSION_SCHEMA_VERSION is the current version of the `DISCUSSION_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const DISCUSSION_SCHEMA_VERSION int = 1
+
+// DiscussionRecord is the JSON-encoded representation of a GitHub `discussion`, `discussion_comment` or `commit_comment` event
+// produced by transformations in this package.
+type DiscussionRecord struct {
+	// Schema is the name of the schema for the record. It is always `DISCUSSION_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event: "discussion", "discussion_comment" or "commit_comment".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event, for example "created", "answered" or "category_changed".
+	Action string `json:"action"`
+	// Repo is the name of the repository the discussion or commit belongs to.
+	Repo string `json:"repo"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository the discussion or commit belongs to.
+	FullName string `json:"full_name"`
+	// Number is the repository-specific number of the discussion. It is zero for `commit_comment` events.
+	Number int `json:"number,omitempty"`
+	// Title is the title of the discussion. It is empty for `commit_comment` events.
+	Title string `json:"title,omitempty"`
+	// Category is the name of the discussion category, for example "Q&A". It is empty for `commit_comment` events.
+	Category string `json:"category,omitempty"`
+	// Answered is a boolean flag indicating whether an answer has been chosen for the discussion.
+	Answered bool `json:"answered"`
+	// Author is the login of the user who wrote the discussion, or the comment for `discussion_comment` and `commit_comment` events.
+	Author string `json:"author"`
+	// Association is the relationship of 'Author' to the repository, for example "OWNER", "MEMBER" or "NONE".
+	Association string `json:"association"`
+	// CommentID is the unique ID of the comment. It is zero for `discussion` events.
+	CommentID int64 `json:"comment_id,omitempty"`
+	// SHA is the hash of the commit that was commented on. It is empty for `discussion` and `discussion_comment` events.
+	SHA string `json:"sha,omitempty"`
+	// Path is the path of the file that was commented on, if a commit comment is attached to a line.
+	Path string `json:"path,omitempty"`
+	// Line is the line of the file that was commented on, if a commit comment is attached to a line.
+	Line int `json:"line,omitempty"`
+	// Body is the body of the discussion or comment.
+	Body string `json:"body,omitempty"`
+	// HTMLURL is the URL of the (HTML) web page for the discussion or comment.
+	HTMLURL string `json:"html_url"`
+}
+
+// discussionCommentExtras contains the `comment` property of `discussion_comment` webhook messages, which are not (yet)
+// defined by go-github.
+type discussionCommentExtras struct {
+	Comment struct {
+		ID                int64          `json:"id"`
+		User              *gogithub.User `json:"user"`
+		AuthorAssociation string         `json:"author_association"`
+		Body              string         `json:"body"`
+		HTMLURL           string         `json:"html_url"`
+	} `json:"comment"`
+}
+
+// discussionCommentEvent is a GitHub `discussion_comment` webhook message. go-github does not (yet) define a type for
+// `discussion_comment` events so the properties they share with `discussion` events are decoded using `gogithub.DiscussionEvent`.
+type discussionCommentEvent struct {
+	gogithub.DiscussionEvent
+	discussionCommentExtras
+}
+
+// commitCommentExtras contains properties of `commit_comment` webhook messages which are not (yet) defined by the go-github
+// `RepositoryComment` type.
+type commitCommentExtras struct {
+	Comment struct {
+		Line              int    `json:"line"`
+		AuthorAssociation string `json:"author_association"`
+	} `json:"comment"`
+}
+
+// newDiscussionRecord returns a new `DiscussionRecord` instance for the event 'event_type' derived from 'event'.
+func newDiscussionRecord(event_type string, event *gogithub.DiscussionEvent) *DiscussionRecord {
+
+	discussion := event.GetDiscussion()
+	repo := event.GetRepo()
+
+	rec := &DiscussionRecord{
+		Schema:      DISCUSSION_SCHEMA,
+		Version:     DISCUSSION_SCHEMA_VERSION,
+		Event:       event_type,
+		Action:      event.GetAction(),
+		Repo:        repo.GetName(),
+		FullName:    repo.GetFullName(),
+		Number:      discussion.GetNumber(),
+		Title:       discussion.GetTitle(),
+		Category:    discussion.GetDiscussionCategory().GetName(),
+		Answered:    discussion.GetAnswerHTMLURL() != "",
+		Author:      discussion.GetUser().GetLogin(),
+		Association: discussion.GetAuthorAssociation(),
+		Body:        discussion.GetBody(),
+		HTMLURL:     discussion.GetHTMLURL(),
+	}
+
+	return rec
+}
+
+// newDiscussionRecordFromDiscussion returns a new `DiscussionRecord` instance derived from 'event'.
+func newDiscussionRecordFromDiscussion(event *gogithub.DiscussionEvent) *DiscussionRecord {
+	return newDiscussionRecord("discussion", event)
+}
+
+// newDiscussionRecordFromComment returns a new `DiscussionRecord` instance derived from 'event' and 'body', the `discussion_comment`
+// webhook message 'event' was unmarshaled from. go-github does not define a type for `discussion_comment` events so the properties
+// they share with `discussion` events are read from 'event' and the comment is read from 'body'. The author, association, body and
+// URL are those of the comment rather than the discussion.
+func newDiscussionRecordFromComment(event *gogithub.DiscussionEvent, body []byte) (*DiscussionRecord, error) {
+
+	var extras discussionCommentExtras
+
+	err := json.Unmarshal(body, &extras)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal discussion comment, %w", err)
+	}
+
+	comment := extras.Comment
+
+	rec := newDiscussionRecord("discussion_comment", event)
+	rec.Author = comment.User.GetLogin()
+	rec.Association = comment.AuthorAssociation
+	rec.CommentID = comment.ID
+	rec.Body = comment.Body
+	rec.HTMLURL = comment.HTMLURL
+
+	return rec, nil
+}
+
+// newDiscussionRecordFromCommitComment returns a new `DiscussionRecord` instance derived from 'event' and 'body', the webhook
+// message 'event' was unmarshaled from.
+func newDiscussionRecordFromCommitComment(event *gogithub.CommitCommentEvent, body []byte) (*DiscussionRecord, error) {
+
+	var extras commitCommentExtras
+
+	err := json.Unmarshal(body, &extras)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal commit comment, %w", err)
+	}
+
+	comment := event.GetComment()
+	repo := event.GetRepo()
+
+	rec := &DiscussionRecord{
+		Schema:      DISCUSSION_SCHEMA,
+		Version:     DISCUSSION_SCHEMA_VERSION,
+		Event:       "commit_comment",
+		Action:      event.GetAction(),
+		Repo:        repo.GetName(),
+		FullName:    repo.GetFullName(),
+		Author:      comment.GetUser().GetLogin(),
+		Association: extras.Comment.AuthorAssociation,
+		CommentID:   comment.GetID(),
+		SHA:         comment.GetCommitID(),
+		Path:        comment.GetPath(),
+		Line:        extras.Comment.Line,
+		Body:        comment.GetBody(),
+		HTMLURL:     comment.GetHTMLURL(),
+	}
+
+	return rec, nil
+}
+
+// discussionRuleFieldNames is the list of field names that rules for discussion events may be evaluated against.
+var discussionRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"title",
+	"category",
+	"author",
+	"association",
+	"sha",
+	"path",
+}
+
+// discussionRuleFields returns the `RuleFields` for 'rec' used to evaluate rules.
+func discussionRuleFields(rec *DiscussionRecord) RuleFields {
+
+	fields := RuleFields{
+		"event":       []string{rec.Event},
+		"action":      []string{rec.Action},
+		"repo":        []string{rec.Repo},
+		"full_name":   []string{rec.FullName},
+		"title":       []string{rec.Title},
+		"category":    []string{rec.Category},
+		"author":      []string{rec.Author},
+		"association": []string{rec.Association},
+		"sha":         []string{rec.SHA},
+		"path":        []string{rec.Path},
+	}
+
+	return fields
+}
+
+// discussionRows returns the CSV rows for 'rec': the name of the repository, the name of the event, the action, the discussion
+// number, the category, the author, the author association, the commit hash, the file path, the line number and the URL.
+func discussionRows(rec *DiscussionRecord) [][]string {
+
+	number := ""
+
+	if rec.Number > 0 {
+		number = strconv.Itoa(rec.Number)
+	}
+
+	line := ""
+
+	if rec.Line > 0 {
+		line = strconv.Itoa(rec.Line)
+	}
+
+	row := []string{rec.Repo, rec.Event, rec.Action, number, rec.Category, rec.Author, rec.Association, rec.SHA, rec.Path, line, rec.HTMLURL}
+	return [][]string{row}
+}
@@ -0,0 +1,487 @@
+package github
+
+import (
+	"encoding/json"
+	"time"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// EVENT_SCHEMA is the name of the schema used to encode normalized GitHub events as JSON.
+const EVENT_SCHEMA string = "event"
+
+// EVENT_SCHEMA_VERSION is the current version of the `EVENT_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const EVENT_SCHEMA_VERSION int = 1
+
+// SUBJECT_COMMIT is the subject type for events about a commit (for example `push` and `status` events).
+const SUBJECT_COMMIT string = "commit"
+
+// SUBJECT_REF is the subject type for events about a Git reference (for example `create` and `delete` events).
+const SUBJECT_REF string = "ref"
+
+// SUBJECT_PULL_REQUEST is the subject type for events about a pull request.
+const SUBJECT_PULL_REQUEST string = "pull_request"
+
+// SUBJECT_ISSUE is the subject type for events about an issue.
+const SUBJECT_ISSUE string = "issue"
+
+// SUBJECT_RELEASE is the subject type for events about a release.
+const SUBJECT_RELEASE string = "release"
+
+// SUBJECT_WORKFLOW_RUN is the subject type for events about a GitHub Actions workflow run.
+const SUBJECT_WORKFLOW_RUN string = "workflow_run"
+
+// SUBJECT_WORKFLOW_JOB is the subject type for events about a GitHub Actions workflow job.
+const SUBJECT_WORKFLOW_JOB string = "workflow_job"
+
+// SUBJECT_CHECK_RUN is the subject type for events about a check run.
+const SUBJECT_CHECK_RUN string = "check_run"
+
+// SUBJECT_CHECK_SUITE is the subject type for events about a check suite.
+const SUBJECT_CHECK_SUITE string = "check_suite"
+
+// SUBJECT_DEPLOYMENT is the subject type for events about a deployment.
+const SUBJECT_DEPLOYMENT string = "deployment"
+
+// SUBJECT_DISCUSSION is the subject type for events about a discussion.
+const SUBJECT_DISCUSSION string = "discussion"
+
+// EventSubject is the thing (pull request, issue, release, workflow run, etc.) that a GitHub event is about.
+type EventSubject struct {
+	// Type is the kind of subject, for example `SUBJECT_PULL_REQUEST`.
+	Type string `json:"type"`
+	// ID is the unique GitHub ID of the subject, if it has one.
+	ID int64 `json:"id,omitempty"`
+	// Number is the repository-specific number of the subject (for example a pull request number), if it has one.
+	Number int `json:"number,omitempty"`
+	// SHA is the hash of the commit associated with the subject, if known.
+	SHA string `json:"sha,omitempty"`
+	// Title is the title (or name) of the subject.
+	Title string `json:"title,omitempty"`
+	// State is the state (or status) of the subject, for example "open" or "completed".
+	State string `json:"state,omitempty"`
+	// HTMLURL is the URL of the (HTML) web page for the subject.
+	HTMLURL string `json:"html_url,omitempty"`
+	// APIURL is the GitHub API URL for the subject.
+	APIURL string `json:"api_url,omitempty"`
+}
+
+// EventRecord is the normalized JSON-encoded representation of any GitHub event produced by the `githubevent://` transformation.
+type EventRecord struct {
+	// Schema is the name of the schema for the record. It is always `EVENT_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event, for example "push" or "pull_request".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event, for example "opened", if present.
+	Action string `json:"action,omitempty"`
+	// Repo is the name of the repository associated with the event.
+	Repo string `json:"repo,omitempty"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository associated with the event.
+	FullName string `json:"full_name,omitempty"`
+	// RepoURL is the URL of the (HTML) web page for the repository associated with the event.
+	RepoURL string `json:"repo_url,omitempty"`
+	// Ref is the Git reference associated with the event, if any.
+	Ref string `json:"ref,omitempty"`
+	// Actor is the login of the user who triggered the event.
+	Actor string `json:"actor,omitempty"`
+	// Subject is the thing the event is about, if any.
+	Subject *EventSubject `json:"subject,omitempty"`
+	// CreatedAt is the RFC3339 encoded time the subject of the event was created, if known.
+	CreatedAt string `json:"created_at,omitempty"`
+	// UpdatedAt is the RFC3339 encoded time the subject of the event was last updated, if known.
+	UpdatedAt string `json:"updated_at,omitempty"`
+	// Payload is the original GitHub event payload. It is only included if explicitly requested.
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// eventAction is implemented by GitHub events that have an action.
+type eventAction interface {
+	GetAction() string
+}
+
+// eventSender is implemented by GitHub events that have a sender.
+type eventSender interface {
+	GetSender() *gogithub.User
+}
+
+// eventRepo is implemented by GitHub events that have a repository.
+type eventRepo interface {
+	GetRepo() *gogithub.Repository
+}
+
+// formatTime returns 't' encoded as an RFC3339 string in UTC, or an empty string if 't' is the zero time.
+func formatTime(t time.Time) string {
+
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// qualifyRef returns 'ref' as a fully-qualified Git reference, for example "refs/heads/main", given its 'ref_type' ("branch" or "tag").
+func qualifyRef(ref string, ref_type string) string {
+
+	if ref == "" {
+		return ""
+	}
+
+	switch ref_type {
+	case "branch":
+		return "refs/heads/" + ref
+	case "tag":
+		return "refs/tags/" + ref
+	default:
+		return ref
+	}
+}
+
+// pullRequestSubject returns a new `EventSubject` instance for 'pr'.
+func pullRequestSubject(pr *gogithub.PullRequest) *EventSubject {
+
+	return &EventSubject{
+		Type:    SUBJECT_PULL_REQUEST,
+		ID:      pr.GetID(),
+		Number:  pr.GetNumber(),
+		SHA:     pr.GetHead().GetSHA(),
+		Title:   pr.GetTitle(),
+		State:   pr.GetState(),
+		HTMLURL: pr.GetHTMLURL(),
+		APIURL:  pr.GetURL(),
+	}
+}
+
+// issueSubject returns a new `EventSubject` instance for 'issue'. Issues which are pull requests are assigned the
+// `SUBJECT_PULL_REQUEST` type.
+func issueSubject(issue *gogithub.Issue) *EventSubject {
+
+	subject_type := SUBJECT_ISSUE
+
+	if issue.IsPullRequest() {
+		subject_type = SUBJECT_PULL_REQUEST
+	}
+
+	return &EventSubject{
+		Type:    subject_type,
+		ID:      issue.GetID(),
+		Number:  issue.GetNumber(),
+		Title:   issue.GetTitle(),
+		State:   issue.GetState(),
+		HTMLURL: issue.GetHTMLURL(),
+		APIURL:  issue.GetURL(),
+	}
+}
+
+// newEventRecord returns a new `EventRecord` instance for 'event', which is expected to be a pointer to the go-github
+// type corresponding to 'event_type' (as returned by `UnmarshalEvent`). Properties common to all events (action,
+// repository and actor) are derived for any event type; the ref, subject and timestamps are derived for event types
+// which have them.
+func newEventRecord(event_type string, event interface{}) *EventRecord {
+
+	rec := &EventRecord{
+		Schema:  EVENT_SCHEMA,
+		Version: EVENT_SCHEMA_VERSION,
+		Event:   event_type,
+	}
+
+	if e, ok := event.(eventAction); ok {
+		rec.Action = e.GetAction()
+	}
+
+	if e, ok := event.(eventSender); ok {
+		rec.Actor = e.GetSender().GetLogin()
+	}
+
+	if e, ok := event.(eventRepo); ok {
+		repo := e.GetRepo()
+		rec.Repo = repo.GetName()
+		rec.FullName = repo.GetFullName()
+		rec.RepoURL = repo.GetHTMLURL()
+	}
+
+	var created_at time.Time
+	var updated_at time.Time
+
+	switch e := event.(type) {
+	case *gogithub.PushEvent:
+
+		repo := e.GetRepo()
+		head := e.GetHeadCommit()
+
+		rec.Repo = repo.GetName()
+		rec.FullName = repo.GetFullName()
+		rec.RepoURL = repo.GetHTMLURL()
+		rec.Ref = e.GetRef()
+
+		rec.Subject = &EventSubject{
+			Type:    SUBJECT_COMMIT,
+			SHA:     e.GetAfter(),
+			Title:   head.GetMessage(),
+			HTMLURL: e.GetCompare(),
+		}
+
+		created_at = head.GetTimestamp().Time
+
+	case *gogithub.CreateEvent:
+
+		rec.Ref = qualifyRef(e.GetRef(), e.GetRefType())
+		rec.Subject = &EventSubject{Type: SUBJECT_REF, Title: e.GetRef()}
+
+	case *gogithub.DeleteEvent:
+
+		rec.Ref = qualifyRef(e.GetRef(), e.GetRefType())
+		rec.Subject = &EventSubject{Type: SUBJECT_REF, Title: e.GetRef()}
+
+	case *gogithub.PullRequestEvent:
+
+		pr := e.GetPullRequest()
+		rec.Ref = qualifyRef(pr.GetHead().GetRef(), "branch")
+		rec.Subject = pullRequestSubject(pr)
+		created_at = pr.GetCreatedAt()
+		updated_at = pr.GetUpdatedAt()
+
+	case *gogithub.PullRequestTargetEvent:
+
+		pr := e.GetPullRequest()
+		rec.Ref = qualifyRef(pr.GetHead().GetRef(), "branch")
+		rec.Subject = pullRequestSubject(pr)
+		created_at = pr.GetCreatedAt()
+		updated_at = pr.GetUpdatedAt()
+
+	case *gogithub.PullRequestReviewEvent:
+
+		pr := e.GetPullRequest()
+		rec.Ref = qualifyRef(pr.GetHead().GetRef(), "branch")
+		rec.Subject = pullRequestSubject(pr)
+		created_at = pr.GetCreatedAt()
+		updated_at = pr.GetUpdatedAt()
+
+	case *gogithub.PullRequestReviewCommentEvent:
+
+		pr := e.GetPullRequest()
+		rec.Ref = qualifyRef(pr.GetHead().GetRef(), "branch")
+		rec.Subject = pullRequestSubject(pr)
+		created_at = pr.GetCreatedAt()
+		updated_at = pr.GetUpdatedAt()
+
+	case *gogithub.PullRequestReviewThreadEvent:
+
+		pr := e.GetPullRequest()
+		rec.Ref = qualifyRef(pr.GetHead().GetRef(), "branch")
+		rec.Subject = pullRequestSubject(pr)
+		created_at = pr.GetCreatedAt()
+		updated_at = pr.GetUpdatedAt()
+
+	case *gogithub.IssuesEvent:
+
+		issue := e.GetIssue()
+		rec.Subject = issueSubject(issue)
+		created_at = issue.GetCreatedAt()
+		updated_at = issue.GetUpdatedAt()
+
+	case *gogithub.IssueCommentEvent:
+
+		issue := e.GetIssue()
+		rec.Subject = issueSubject(issue)
+		created_at = issue.GetCreatedAt()
+		updated_at = issue.GetUpdatedAt()
+
+	case *gogithub.ReleaseEvent:
+
+		release := e.GetRelease()
+		rec.Ref = qualifyRef(release.GetTagName(), "tag")
+
+		state := "published"
+
+		if release.GetDraft() {
+			state = "draft"
+		}
+
+		rec.Subject = &EventSubject{
+			Type:    SUBJECT_RELEASE,
+			ID:      release.GetID(),
+			Title:   release.GetName(),
+			State:   state,
+			HTMLURL: release.GetHTMLURL(),
+			APIURL:  release.GetURL(),
+		}
+
+		created_at = release.GetCreatedAt().Time
+		updated_at = release.GetPublishedAt().Time
+
+	case *gogithub.WorkflowRunEvent:
+
+		run := e.GetWorkflowRun()
+		rec.Ref = qualifyRef(run.GetHeadBranch(), "branch")
+
+		rec.Subject = &EventSubject{
+			Type:    SUBJECT_WORKFLOW_RUN,
+			ID:      run.GetID(),
+			Number:  run.GetRunNumber(),
+			SHA:     run.GetHeadSHA(),
+			Title:   run.GetName(),
+			State:   run.GetStatus(),
+			HTMLURL: run.GetHTMLURL(),
+			APIURL:  run.GetURL(),
+		}
+
+		created_at = run.GetCreatedAt().Time
+		updated_at = run.GetUpdatedAt().Time
+
+	case *gogithub.WorkflowJobEvent:
+
+		job := e.GetWorkflowJob()
+
+		rec.Subject = &EventSubject{
+			Type:    SUBJECT_WORKFLOW_JOB,
+			ID:      job.GetID(),
+			SHA:     job.GetHeadSHA(),
+			Title:   job.GetName(),
+			State:   job.GetStatus(),
+			HTMLURL: job.GetHTMLURL(),
+			APIURL:  job.GetURL(),
+		}
+
+		created_at = job.GetStartedAt().Time
+		updated_at = job.GetCompletedAt().Time
+
+	case *gogithub.CheckRunEvent:
+
+		run := e.GetCheckRun()
+
+		rec.Subject = &EventSubject{
+			Type:    SUBJECT_CHECK_RUN,
+			ID:      run.GetID(),
+			SHA:     run.GetHeadSHA(),
+			Title:   run.GetName(),
+			State:   run.GetStatus(),
+			HTMLURL: run.GetHTMLURL(),
+			APIURL:  run.GetURL(),
+		}
+
+		created_at = run.GetStartedAt().Time
+		updated_at = run.GetCompletedAt().Time
+
+	case *gogithub.CheckSuiteEvent:
+
+		suite := e.GetCheckSuite()
+		rec.Ref = qualifyRef(suite.GetHeadBranch(), "branch")
+
+		rec.Subject = &EventSubject{
+			Type:   SUBJECT_CHECK_SUITE,
+			ID:     suite.GetID(),
+			SHA:    suite.GetHeadSHA(),
+			State:  suite.GetStatus(),
+			APIURL: suite.GetURL(),
+		}
+
+		created_at = suite.GetCreatedAt().Time
+		updated_at = suite.GetUpdatedAt().Time
+
+	case *gogithub.StatusEvent:
+
+		rec.Subject = &EventSubject{
+			Type:    SUBJECT_COMMIT,
+			ID:      e.GetID(),
+			SHA:     e.GetSHA(),
+			Title:   e.GetContext(),
+			State:   e.GetState(),
+			HTMLURL: e.GetTargetURL(),
+		}
+
+		created_at = e.GetCreatedAt().Time
+		updated_at = e.GetUpdatedAt().Time
+
+	case *gogithub.DeploymentEvent:
+
+		d := e.GetDeployment()
+		rec.Ref = d.GetRef()
+
+		rec.Subject = &EventSubject{
+			Type:   SUBJECT_DEPLOYMENT,
+			ID:     d.GetID(),
+			SHA:    d.GetSHA(),
+			Title:  d.GetEnvironment(),
+			APIURL: d.GetURL(),
+		}
+
+		created_at = d.GetCreatedAt().Time
+		updated_at = d.GetUpdatedAt().Time
+
+	case *gogithub.DeploymentStatusEvent:
+
+		d := e.GetDeployment()
+		status := e.GetDeploymentStatus()
+		rec.Ref = d.GetRef()
+
+		rec.Subject = &EventSubject{
+			Type:   SUBJECT_DEPLOYMENT,
+			ID:     d.GetID(),
+			SHA:    d.GetSHA(),
+			Title:  d.GetEnvironment(),
+			State:  status.GetState(),
+			APIURL: d.GetURL(),
+		}
+
+		created_at = d.GetCreatedAt().Time
+		updated_at = status.GetUpdatedAt().Time
+
+	case *gogithub.DiscussionEvent:
+
+		d := e.GetDiscussion()
+
+		rec.Subject = &EventSubject{
+			Type:    SUBJECT_DISCUSSION,
+			ID:      d.GetID(),
+			Number:  d.GetNumber(),
+			Title:   d.GetTitle(),
+			State:   d.GetState(),
+			HTMLURL: d.GetHTMLURL(),
+		}
+
+		created_at = d.GetCreatedAt().Time
+		updated_at = d.GetUpdatedAt().Time
+	}
+
+	rec.CreatedAt = formatTime(created_at)
+	rec.UpdatedAt = formatTime(updated_at)
+
+	return rec
+}
+
+// eventRuleFieldNames is the list of field names that rules for normalized GitHub events may be evaluated against.
+var eventRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"ref",
+	"actor",
+	"subject_type",
+	"subject_state",
+	"subject_title",
+}
+
+// eventRuleFields returns the `RuleFields` for 'rec' used to evaluate rules.
+func eventRuleFields(rec *EventRecord) RuleFields {
+
+	fields := RuleFields{
+		"event":     []string{rec.Event},
+		"action":    []string{rec.Action},
+		"repo":      []string{rec.Repo},
+		"full_name": []string{rec.FullName},
+		"ref":       []string{rec.Ref},
+		"actor":     []string{rec.Actor},
+	}
+
+	if rec.Subject != nil {
+		fields["subject_type"] = []string{rec.Subject.Type}
+		fields["subject_state"] = []string{rec.Subject.State}
+		fields["subject_title"] = []string{rec.Subject.Title}
+	}
+
+	return fields
+}
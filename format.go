@@ -0,0 +1,89 @@
+package github
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+)
+
+// FORMAT_CSV signals that transformations should output CSV-encoded rows. This is the default format.
+const FORMAT_CSV string = "csv"
+
+// FORMAT_JSON signals that transformations should output a single JSON-encoded record.
+const FORMAT_JSON string = "json"
+
+// FORMAT_NDJSON signals that transformations should output newline-delimited JSON-encoded records.
+const FORMAT_NDJSON string = "ndjson"
+
+// parseFormat returns the value of the `?format=` parameter in 'q' ensuring that it is a valid
+// output format. If the parameter is empty then `FORMAT_CSV` is returned.
+func parseFormat(q url.Values) (string, error) {
+
+	format := strings.ToLower(q.Get("format"))
+
+	switch format {
+	case "":
+		return FORMAT_CSV, nil
+	case FORMAT_CSV, FORMAT_JSON, FORMAT_NDJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("Invalid ?format= parameter '%s'", format)
+	}
+}
+
+// marshalJSON returns the JSON encoding of 'v' terminated by a newline.
+func marshalJSON(v interface{}) ([]byte, *webhookd.WebhookError) {
+
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+
+	err := enc.Encode(v)
+
+	if err != nil {
+		err := &webhookd.WebhookError{Code: http.StatusInternalServerError, Message: err.Error()}
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// marshalNDJSON returns the JSON encoding of each element in 'rows' as newline-delimited records.
+func marshalNDJSON[T any](rows []T) ([]byte, *webhookd.WebhookError) {
+
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+
+	for _, r := range rows {
+
+		err := enc.Encode(r)
+
+		if err != nil {
+			err := &webhookd.WebhookError{Code: http.StatusInternalServerError, Message: err.Error()}
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// marshalCSV returns the CSV encoding of 'rows'.
+func marshalCSV(rows [][]string) ([]byte, *webhookd.WebhookError) {
+
+	buf := new(bytes.Buffer)
+	wr := csv.NewWriter(buf)
+
+	err := wr.WriteAll(rows)
+
+	if err != nil {
+		err := &webhookd.WebhookError{Code: http.StatusInternalServerError, Message: err.Error()}
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
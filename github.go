@@ -23,70 +23,45 @@ func GenerateSignature(body string, secret string) (string, error) {
 	return sig, nil
 }
 
-// this doesn't really work as I'd like it only returns an interface{}
-// and not a typed thing that the compiler knows what to do with after
-// the fact... (20161017/thisisaaronland)
+// UnmarshalEventAs unmarshals a GitHub event message derived from 'body' in to a new instance of 'T', which is expected
+// to be one of the go-github event types. For example:
+//
+//	event, err := UnmarshalEventAs[gogithub.PushEvent](body)
+func UnmarshalEventAs[T any](body []byte) (*T, error) {
 
-// UnmarshalEvent unmarshals a GitHub event message derived from 'body' in to an interface of type 'event_type'.
-func UnmarshalEvent(event_type string, body []byte) (interface{}, error) {
+	event := new(T)
 
-	var event interface{}
-	ok := true
-
-	switch event_type {
-	case "commit_comment":
-		event = gogithub.CommitCommentEvent{}
-	case "create":
-		event = gogithub.CreateEvent{}
-	case "delete":
-		event = gogithub.DeleteEvent{}
-	case "deployment":
-		event = gogithub.DeploymentEvent{}
-	case "deployment_status":
-		event = gogithub.DeploymentStatusEvent{}
-	case "fork":
-		event = gogithub.ForkEvent{}
-	case "gollum":
-		event = gogithub.GollumEvent{}
-	case "issue_comment":
-		event = gogithub.IssueCommentEvent{}
-	case "issues":
-		event = gogithub.IssuesEvent{}
-	case "member":
-		event = gogithub.MemberEvent{}
-	case "membership":
-		event = gogithub.MembershipEvent{}
-	case "page_build":
-		event = gogithub.PageBuildEvent{}
-	case "public":
-		event = gogithub.PublicEvent{}
-	case "pull_request_review_comment":
-		event = gogithub.PullRequestReviewCommentEvent{}
-	// case "pull_request_review":
-	// 	event = gogithub.PullRequestReviewEvent{}
-	case "pull_request":
-		event = gogithub.PullRequestEvent{}
-	case "push":
-		event = gogithub.PushEvent{}
-	case "repository":
-		event = gogithub.RepositoryEvent{}
-	case "release":
-		event = gogithub.ReleaseEvent{}
-	case "status":
-		event = gogithub.StatusEvent{}
-	case "team_add":
-		event = gogithub.TeamAddEvent{}
-	case "watch":
-		event = gogithub.WatchEvent{}
-	default:
-		ok = false
+	err := json.Unmarshal(body, event)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal body, %w", err)
 	}
 
-	if !ok {
-		return nil, fmt.Errorf("Unknown event type: %s", event_type)
+	return event, nil
+}
+
+// UnmarshalEvent unmarshals a GitHub event message derived from 'body' in to a pointer to the go-github type
+// corresponding to 'event_type' (for example `*github.PushEvent` for "push") returned as an interface{}. Callers
+// can use a type switch or type assertion to recover the concrete type. Event types are mapped to go-github types
+// using the go-github `ParseWebHook` function, except for those event types listed in `localEventTypes`.
+func UnmarshalEvent(event_type string, body []byte) (interface{}, error) {
+
+	err := validateEventType(event_type)
+
+	if err != nil {
+		return nil, err
 	}
 
-	err := json.Unmarshal(body, &event)
+	var event interface{}
+
+	new_event, ok := localEventTypes[event_type]
+
+	if ok {
+		event = new_event()
+		err = json.Unmarshal(body, event)
+	} else {
+		event, err = gogithub.ParseWebHook(event_type, body)
+	}
 
 	if err != nil {
 		return nil, fmt.Errorf("Failed to unmarshal body, %w", err)
@@ -94,3 +69,30 @@ func UnmarshalEvent(event_type string, body []byte) (interface{}, error) {
 
 	return event, nil
 }
+
+// localEventTypes maps event types which the go-github `ParseWebHook` function does not know about (or can not decode)
+// to functions returning a pointer to a new (empty) instance of the type used to decode them.
+var localEventTypes = map[string]func() interface{}{
+	"discussion_comment": func() interface{} { return new(discussionCommentEvent) },
+	"package":            func() interface{} { return new(packageEvent) },
+	"registry_package":   func() interface{} { return new(packageEvent) },
+	"security_advisory":  func() interface{} { return new(gogithub.SecurityAdvisoryEvent) },
+}
+
+// validateEventType returns an error if 'event_type' is not an event type that can be unmarshaled by `UnmarshalEvent`.
+func validateEventType(event_type string) error {
+
+	_, ok := localEventTypes[event_type]
+
+	if ok {
+		return nil
+	}
+
+	_, err := gogithub.ParseWebHook(event_type, []byte(`{}`))
+
+	if err != nil {
+		return fmt.Errorf("Unknown event type: %s", event_type)
+	}
+
+	return nil
+}
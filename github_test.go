@@ -3,7 +3,11 @@ package github
 import (
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	gogithub "github.com/google/go-github/v48/github"
 )
 
 func TestGenerateSignature(t *testing.T) {
@@ -55,9 +59,225 @@ func TestUnmarshalEvent(t *testing.T) {
 		t.Fatalf("Failed to read %s, %v", msg, err)
 	}
 
-	_, err = UnmarshalEvent("push", body)
+	ev, err := UnmarshalEvent("push", body)
 
 	if err != nil {
 		t.Fatalf("Unable to unmarshal push event, %v", err)
 	}
+
+	push, ok := ev.(*gogithub.PushEvent)
+
+	if !ok {
+		t.Fatalf("Unexpected event type: %T", ev)
+	}
+
+	if push.GetRepo().GetName() == "" {
+		t.Fatalf("Push event is missing repository name")
+	}
+
+	_, err = UnmarshalEvent("not_an_event", body)
+
+	if err == nil {
+		t.Fatalf("Expected error for unknown event type")
+	}
+}
+
+func TestUnmarshalEventTypes(t *testing.T) {
+
+	tests := []struct {
+		event_type string
+		expected   interface{}
+	}{
+		{"branch_protection_rule", &gogithub.BranchProtectionRuleEvent{}},
+		{"check_run", &gogithub.CheckRunEvent{}},
+		{"check_suite", &gogithub.CheckSuiteEvent{}},
+		{"code_scanning_alert", &gogithub.CodeScanningAlertEvent{}},
+		{"commit_comment", &gogithub.CommitCommentEvent{}},
+		{"content_reference", &gogithub.ContentReferenceEvent{}},
+		{"create", &gogithub.CreateEvent{}},
+		{"delete", &gogithub.DeleteEvent{}},
+		{"deploy_key", &gogithub.DeployKeyEvent{}},
+		{"deployment", &gogithub.DeploymentEvent{}},
+		{"deployment_status", &gogithub.DeploymentStatusEvent{}},
+		{"discussion", &gogithub.DiscussionEvent{}},
+		{"discussion_comment", &discussionCommentEvent{}},
+		{"fork", &gogithub.ForkEvent{}},
+		{"github_app_authorization", &gogithub.GitHubAppAuthorizationEvent{}},
+		{"gollum", &gogithub.GollumEvent{}},
+		{"installation", &gogithub.InstallationEvent{}},
+		{"installation_repositories", &gogithub.InstallationRepositoriesEvent{}},
+		{"issue_comment", &gogithub.IssueCommentEvent{}},
+		{"issues", &gogithub.IssuesEvent{}},
+		{"label", &gogithub.LabelEvent{}},
+		{"marketplace_purchase", &gogithub.MarketplacePurchaseEvent{}},
+		{"member", &gogithub.MemberEvent{}},
+		{"membership", &gogithub.MembershipEvent{}},
+		{"merge_group", &gogithub.MergeGroupEvent{}},
+		{"meta", &gogithub.MetaEvent{}},
+		{"milestone", &gogithub.MilestoneEvent{}},
+		{"org_block", &gogithub.OrgBlockEvent{}},
+		{"organization", &gogithub.OrganizationEvent{}},
+		{"package", &packageEvent{}},
+		{"page_build", &gogithub.PageBuildEvent{}},
+		{"ping", &gogithub.PingEvent{}},
+		{"project", &gogithub.ProjectEvent{}},
+		{"project_card", &gogithub.ProjectCardEvent{}},
+		{"project_column", &gogithub.ProjectColumnEvent{}},
+		{"public", &gogithub.PublicEvent{}},
+		{"pull_request", &gogithub.PullRequestEvent{}},
+		{"pull_request_review", &gogithub.PullRequestReviewEvent{}},
+		{"pull_request_review_comment", &gogithub.PullRequestReviewCommentEvent{}},
+		{"pull_request_review_thread", &gogithub.PullRequestReviewThreadEvent{}},
+		{"pull_request_target", &gogithub.PullRequestTargetEvent{}},
+		{"push", &gogithub.PushEvent{}},
+		{"registry_package", &packageEvent{}},
+		{"release", &gogithub.ReleaseEvent{}},
+		{"repository", &gogithub.RepositoryEvent{}},
+		{"repository_dispatch", &gogithub.RepositoryDispatchEvent{}},
+		{"repository_import", &gogithub.RepositoryImportEvent{}},
+		{"repository_vulnerability_alert", &gogithub.RepositoryVulnerabilityAlertEvent{}},
+		{"secret_scanning_alert", &gogithub.SecretScanningAlertEvent{}},
+		{"security_advisory", &gogithub.SecurityAdvisoryEvent{}},
+		{"star", &gogithub.StarEvent{}},
+		{"status", &gogithub.StatusEvent{}},
+		{"team", &gogithub.TeamEvent{}},
+		{"team_add", &gogithub.TeamAddEvent{}},
+		{"user", &gogithub.UserEvent{}},
+		{"watch", &gogithub.WatchEvent{}},
+		{"workflow_dispatch", &gogithub.WorkflowDispatchEvent{}},
+		{"workflow_job", &gogithub.WorkflowJobEvent{}},
+		{"workflow_run", &gogithub.WorkflowRunEvent{}},
+	}
+
+	body := []byte(`{}`)
+
+	for _, test := range tests {
+
+		ev, err := UnmarshalEvent(test.event_type, body)
+
+		if err != nil {
+			t.Fatalf("Failed to unmarshal %s event, %v", test.event_type, err)
+		}
+
+		if reflect.TypeOf(ev) != reflect.TypeOf(test.expected) {
+			t.Fatalf("Unexpected type for %s event: %T", test.event_type, ev)
+		}
+	}
+}
+
+func TestUnmarshalEventFixtures(t *testing.T) {
+
+	tests := []struct {
+		event_type string
+		fixture    string
+		expected   interface{}
+	}{
+		{"branch_protection_rule", "branch_protection_rule.json", &gogithub.BranchProtectionRuleEvent{}},
+		{"check_run", "check_run.json", &gogithub.CheckRunEvent{}},
+		{"check_suite", "check_suite.json", &gogithub.CheckSuiteEvent{}},
+		{"code_scanning_alert", "code_scanning_alert.json", &gogithub.CodeScanningAlertEvent{}},
+		{"commit_comment", "commit_comment.json", &gogithub.CommitCommentEvent{}},
+		{"create", "create_tag.json", &gogithub.CreateEvent{}},
+		{"deploy_key", "deploy_key.json", &gogithub.DeployKeyEvent{}},
+		{"deployment", "deployment.json", &gogithub.DeploymentEvent{}},
+		{"deployment_status", "deployment_status.json", &gogithub.DeploymentStatusEvent{}},
+		{"discussion", "discussion.json", &gogithub.DiscussionEvent{}},
+		{"discussion_comment", "discussion_comment.json", &discussionCommentEvent{}},
+		{"fork", "fork.json", &gogithub.ForkEvent{}},
+		{"gollum", "gollum.json", &gogithub.GollumEvent{}},
+		{"issue_comment", "issue_comment.json", &gogithub.IssueCommentEvent{}},
+		{"issues", "issues.json", &gogithub.IssuesEvent{}},
+		{"member", "member.json", &gogithub.MemberEvent{}},
+		{"package", "package.json", &packageEvent{}},
+		{"public", "public.json", &gogithub.PublicEvent{}},
+		{"pull_request", "pull_request.json", &gogithub.PullRequestEvent{}},
+		{"pull_request_review", "pull_request_review.json", &gogithub.PullRequestReviewEvent{}},
+		{"pull_request_review_comment", "pull_request_review_comment.json", &gogithub.PullRequestReviewCommentEvent{}},
+		{"pull_request_review_thread", "pull_request_review_thread.json", &gogithub.PullRequestReviewThreadEvent{}},
+		{"push", "push.json", &gogithub.PushEvent{}},
+		{"registry_package", "registry_package.json", &packageEvent{}},
+		{"release", "release.json", &gogithub.ReleaseEvent{}},
+		{"repository", "repository_renamed.json", &gogithub.RepositoryEvent{}},
+		{"repository_vulnerability_alert", "repository_vulnerability_alert.json", &gogithub.RepositoryVulnerabilityAlertEvent{}},
+		{"secret_scanning_alert", "secret_scanning_alert.json", &gogithub.SecretScanningAlertEvent{}},
+		{"security_advisory", "security_advisory.json", &gogithub.SecurityAdvisoryEvent{}},
+		{"status", "status.json", &gogithub.StatusEvent{}},
+		{"workflow_job", "workflow_job.json", &gogithub.WorkflowJobEvent{}},
+		{"workflow_run", "workflow_run.json", &gogithub.WorkflowRunEvent{}},
+	}
+
+	for _, test := range tests {
+
+		body := readFixture(t, filepath.Join("fixtures/events", test.fixture))
+
+		ev, err := UnmarshalEvent(test.event_type, body)
+
+		if err != nil {
+			t.Fatalf("Failed to unmarshal %s event, %v", test.event_type, err)
+		}
+
+		if reflect.TypeOf(ev) != reflect.TypeOf(test.expected) {
+			t.Fatalf("Unexpected type for %s event: %T", test.event_type, ev)
+		}
+
+		e, ok := ev.(eventRepo)
+
+		if ok && e.GetRepo().GetName() == "" {
+			t.Fatalf("Failed to unmarshal repository for %s event", test.event_type)
+		}
+	}
+}
+
+func TestUnmarshalEventAs(t *testing.T) {
+
+	msg := "fixtures/events/push.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	push, err := UnmarshalEventAs[gogithub.PushEvent](body)
+
+	if err != nil {
+		t.Fatalf("Unable to unmarshal push event, %v", err)
+	}
+
+	if push.GetRef() != "refs/heads/main" {
+		t.Fatalf("Unexpected ref: %s", push.GetRef())
+	}
+
+	_, err = UnmarshalEventAs[gogithub.PushEvent]([]byte(`{"ref":`))
+
+	if err == nil {
+		t.Fatalf("Expected error for invalid body")
+	}
+}
+
+// readFixture returns the contents of the fixture file 'msg', failing 't' if it can not be read.
+func readFixture(t *testing.T, msg string) []byte {
+
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	return body
 }
@@ -6,6 +6,7 @@ require (
 	github.com/google/go-github/v48 v48.1.0
 	github.com/sfomuseum/go-flags v0.10.0
 	github.com/whosonfirst/go-webhookd/v3 v3.2.0
+	gocloud.dev v0.27.0
 )
 
 require (
@@ -21,7 +22,6 @@ require (
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/googleapis/gax-go/v2 v2.4.0 // indirect
 	go.opencensus.io v0.23.0 // indirect
-	gocloud.dev v0.27.0 // indirect
 	golang.org/x/crypto v0.17.0 // indirect
 	golang.org/x/net v0.10.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
@@ -0,0 +1,296 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+)
+
+// EVENT_INFER is the value used by transformations, in place of an event name, to indicate that the type of each
+// event should be inferred from its payload.
+const EVENT_INFER string = "infer"
+
+// Confidence is the level of confidence with which the type of a GitHub event was inferred from its payload.
+type Confidence int
+
+// CONFIDENCE_NONE indicates that the type of an event could not be inferred.
+const CONFIDENCE_NONE Confidence = 0
+
+// CONFIDENCE_LOW indicates that the type of an event was inferred from properties shared by more than one event type.
+const CONFIDENCE_LOW Confidence = 1
+
+// CONFIDENCE_MEDIUM indicates that the type of an event was inferred from properties which are usually, but not always,
+// specific to that event type.
+const CONFIDENCE_MEDIUM Confidence = 2
+
+// CONFIDENCE_HIGH indicates that the type of an event was inferred from properties which are specific to that event type.
+const CONFIDENCE_HIGH Confidence = 3
+
+// String returns the string representation of 'c'.
+func (c Confidence) String() string {
+
+	switch c {
+	case CONFIDENCE_LOW:
+		return "low"
+	case CONFIDENCE_MEDIUM:
+		return "medium"
+	case CONFIDENCE_HIGH:
+		return "high"
+	default:
+		return "none"
+	}
+}
+
+// ParseConfidence returns the `Confidence` value for 's' which is expected to be one of "low", "medium" or "high".
+func ParseConfidence(s string) (Confidence, error) {
+
+	switch strings.ToLower(s) {
+	case "low":
+		return CONFIDENCE_LOW, nil
+	case "medium":
+		return CONFIDENCE_MEDIUM, nil
+	case "high":
+		return CONFIDENCE_HIGH, nil
+	default:
+		return CONFIDENCE_NONE, fmt.Errorf("Invalid confidence level '%s'", s)
+	}
+}
+
+// eventSignature is the set of (non-null) properties which identify the payload of a GitHub event type.
+type eventSignature struct {
+	// event is the name of the GitHub event type.
+	event string
+	// keys is the list of properties that must be present in a payload. Nested properties are expressed as 'parent.child'.
+	keys []string
+	// confidence is the confidence with which a payload matching 'keys' is assumed to be an 'event' event.
+	confidence Confidence
+}
+
+// eventSignatures is the list of event types which can be inferred from their payloads. Event types whose payloads are
+// indistinguishable from other events (for example "pull_request_target" which is identical to "pull_request", or
+// "repository" and "public" which only contain common properties) are deliberately excluded.
+var eventSignatures = []*eventSignature{
+	{"push", []string{"pusher", "commits"}, CONFIDENCE_HIGH},
+	{"create", []string{"ref", "ref_type", "pusher_type", "master_branch"}, CONFIDENCE_HIGH},
+	{"delete", []string{"ref", "ref_type", "pusher_type"}, CONFIDENCE_MEDIUM},
+	{"pull_request", []string{"pull_request", "number"}, CONFIDENCE_HIGH},
+	{"pull_request_review", []string{"pull_request", "review"}, CONFIDENCE_HIGH},
+	{"pull_request_review_comment", []string{"pull_request", "comment", "comment.commit_id"}, CONFIDENCE_HIGH},
+	{"pull_request_review_thread", []string{"pull_request", "thread"}, CONFIDENCE_HIGH},
+	{"issues", []string{"issue"}, CONFIDENCE_MEDIUM},
+	{"issue_comment", []string{"issue", "comment"}, CONFIDENCE_HIGH},
+	{"commit_comment", []string{"comment", "comment.commit_id"}, CONFIDENCE_MEDIUM},
+	{"discussion", []string{"discussion"}, CONFIDENCE_MEDIUM},
+	{"discussion_comment", []string{"discussion", "comment"}, CONFIDENCE_HIGH},
+	{"release", []string{"release"}, CONFIDENCE_HIGH},
+	{"workflow_run", []string{"workflow_run"}, CONFIDENCE_HIGH},
+	{"workflow_job", []string{"workflow_job"}, CONFIDENCE_HIGH},
+	{"workflow_dispatch", []string{"workflow", "inputs"}, CONFIDENCE_MEDIUM},
+	{"check_run", []string{"check_run"}, CONFIDENCE_HIGH},
+	{"check_suite", []string{"check_suite"}, CONFIDENCE_HIGH},
+	{"status", []string{"sha", "state", "context"}, CONFIDENCE_HIGH},
+	{"deployment", []string{"deployment"}, CONFIDENCE_MEDIUM},
+	{"deployment_status", []string{"deployment", "deployment_status"}, CONFIDENCE_HIGH},
+	{"gollum", []string{"pages"}, CONFIDENCE_HIGH},
+	{"fork", []string{"forkee"}, CONFIDENCE_HIGH},
+	{"member", []string{"member"}, CONFIDENCE_LOW},
+	{"membership", []string{"member", "team", "scope"}, CONFIDENCE_MEDIUM},
+	{"ping", []string{"zen", "hook_id"}, CONFIDENCE_HIGH},
+	{"merge_group", []string{"merge_group"}, CONFIDENCE_HIGH},
+	{"repository_dispatch", []string{"client_payload"}, CONFIDENCE_HIGH},
+	{"page_build", []string{"build"}, CONFIDENCE_MEDIUM},
+	{"package", []string{"package"}, CONFIDENCE_MEDIUM},
+	{"registry_package", []string{"registry_package"}, CONFIDENCE_HIGH},
+	{"code_scanning_alert", []string{"alert", "commit_oid"}, CONFIDENCE_HIGH},
+	{"secret_scanning_alert", []string{"alert", "alert.secret_type"}, CONFIDENCE_HIGH},
+	{"repository_vulnerability_alert", []string{"alert", "alert.affected_package_name"}, CONFIDENCE_HIGH},
+	{"security_advisory", []string{"security_advisory"}, CONFIDENCE_HIGH},
+	{"branch_protection_rule", []string{"rule"}, CONFIDENCE_MEDIUM},
+	{"deploy_key", []string{"key"}, CONFIDENCE_MEDIUM},
+	{"project", []string{"project"}, CONFIDENCE_MEDIUM},
+	{"project_card", []string{"project_card"}, CONFIDENCE_HIGH},
+	{"project_column", []string{"project_column"}, CONFIDENCE_HIGH},
+}
+
+// hasKey returns a boolean value indicating whether 'key' (which may be expressed as 'parent.child') is present, and
+// not null, in 'payload'.
+func hasKey(payload map[string]json.RawMessage, key string) bool {
+
+	parts := strings.SplitN(key, ".", 2)
+
+	raw, ok := payload[parts[0]]
+
+	if !ok || string(raw) == "null" {
+		return false
+	}
+
+	if len(parts) == 1 {
+		return true
+	}
+
+	var child map[string]json.RawMessage
+
+	err := json.Unmarshal(raw, &child)
+
+	if err != nil {
+		return false
+	}
+
+	return hasKey(child, parts[1])
+}
+
+// isSubset returns a boolean value indicating whether every element in 'a' is also in 'b'.
+func isSubset(a []string, b []string) bool {
+
+	for _, k := range a {
+
+		found := false
+
+		for _, other := range b {
+
+			if k == other {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// InferEventType returns the name of the GitHub event type for 'body', derived from the properties present in the payload,
+// along with the confidence of that inference. If more than one signature matches the most specific match (the signature
+// whose properties are a superset of the others) is used. If there are competing matches, none of which is more specific
+// than the others, the payload is considered ambiguous and an error is returned rather than guessing.
+func InferEventType(body []byte) (string, Confidence, error) {
+
+	var payload map[string]json.RawMessage
+
+	err := json.Unmarshal(body, &payload)
+
+	if err != nil {
+		return "", CONFIDENCE_NONE, fmt.Errorf("Failed to unmarshal body, %w", err)
+	}
+
+	matches := make([]*eventSignature, 0)
+
+	for _, sig := range eventSignatures {
+
+		ok := true
+
+		for _, k := range sig.keys {
+
+			if !hasKey(payload, k) {
+				ok = false
+				break
+			}
+		}
+
+		if ok {
+			matches = append(matches, sig)
+		}
+	}
+
+	if len(matches) == 0 {
+		return "", CONFIDENCE_NONE, fmt.Errorf("Unable to infer event type from payload")
+	}
+
+	candidates := make([]*eventSignature, 0)
+
+	for _, sig := range matches {
+
+		dominated := false
+
+		for _, other := range matches {
+
+			if other != sig && len(other.keys) > len(sig.keys) && isSubset(sig.keys, other.keys) {
+				dominated = true
+				break
+			}
+		}
+
+		if !dominated {
+			candidates = append(candidates, sig)
+		}
+	}
+
+	if len(candidates) > 1 {
+
+		names := make([]string, len(candidates))
+
+		for idx, sig := range candidates {
+			names[idx] = sig.event
+		}
+
+		sort.Strings(names)
+
+		return "", CONFIDENCE_NONE, fmt.Errorf("Ambiguous payload, could be any of: %s", strings.Join(names, ", "))
+	}
+
+	return candidates[0].event, candidates[0].confidence, nil
+}
+
+// parseEventParams returns the values of the `?event=` and `?min_confidence=` parameters in 'q'. If `?event=` is empty
+// then 'default_event' is returned. If `?min_confidence=` is empty then `CONFIDENCE_HIGH` is returned.
+func parseEventParams(q url.Values, default_event string) (string, Confidence, error) {
+
+	event_type := q.Get("event")
+
+	if event_type == "" {
+		event_type = default_event
+	}
+
+	min_confidence := CONFIDENCE_HIGH
+
+	if q.Has("min_confidence") {
+
+		c, err := ParseConfidence(q.Get("min_confidence"))
+
+		if err != nil {
+			return "", CONFIDENCE_NONE, fmt.Errorf("Failed to parse ?min_confidence= parameter, %w", err)
+		}
+
+		min_confidence = c
+	}
+
+	return event_type, min_confidence, nil
+}
+
+// resolveEventType returns 'event_type' unless it is `EVENT_INFER` in which case the event type is inferred from 'body'.
+// Inferred event types with a confidence lower than 'min_confidence' are rejected and inferred `ping` events return an
+// error with code `webhookd.UnhandledEvent`.
+func resolveEventType(event_type string, min_confidence Confidence, body []byte) (string, *webhookd.WebhookError) {
+
+	if event_type != EVENT_INFER {
+		return event_type, nil
+	}
+
+	inferred, confidence, err := InferEventType(body)
+
+	if err != nil {
+		err := &webhookd.WebhookError{Code: http.StatusBadRequest, Message: err.Error()}
+		return "", err
+	}
+
+	if confidence < min_confidence {
+		msg := fmt.Sprintf("Inferred event type '%s' has %s confidence", inferred, confidence)
+		err := &webhookd.WebhookError{Code: http.StatusBadRequest, Message: msg}
+		return "", err
+	}
+
+	if inferred == "ping" {
+		err := &webhookd.WebhookError{Code: webhookd.UnhandledEvent, Message: "ping message is a no-op"}
+		return "", err
+	}
+
+	return inferred, nil
+}
@@ -0,0 +1,128 @@
+package github
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+)
+
+func TestInferEventType(t *testing.T) {
+
+	tests := []struct {
+		body       string
+		event      string
+		confidence Confidence
+	}{
+		{`{"ref":"refs/heads/main","pusher":{"name":"bot"},"commits":[]}`, "push", CONFIDENCE_HIGH},
+		{`{"ref":"v1.0","ref_type":"tag","pusher_type":"user","master_branch":"main"}`, "create", CONFIDENCE_HIGH},
+		{`{"ref":"v1.0","ref_type":"tag","pusher_type":"user"}`, "delete", CONFIDENCE_MEDIUM},
+		{`{"action":"opened","number":1,"pull_request":{"id":1}}`, "pull_request", CONFIDENCE_HIGH},
+		{`{"action":"submitted","review":{"id":1},"pull_request":{"id":1}}`, "pull_request_review", CONFIDENCE_HIGH},
+		{`{"action":"created","comment":{"id":1,"commit_id":"abc"},"pull_request":{"id":1}}`, "pull_request_review_comment", CONFIDENCE_HIGH},
+		{`{"action":"created","comment":{"id":1,"commit_id":"abc"}}`, "commit_comment", CONFIDENCE_MEDIUM},
+		{`{"action":"opened","issue":{"id":1}}`, "issues", CONFIDENCE_MEDIUM},
+		{`{"action":"created","issue":{"id":1},"comment":{"id":1}}`, "issue_comment", CONFIDENCE_HIGH},
+		{`{"action":"completed","workflow_run":{"id":1},"workflow":{"id":1}}`, "workflow_run", CONFIDENCE_HIGH},
+		{`{"action":"published","release":{"id":1}}`, "release", CONFIDENCE_HIGH},
+		{`{"deployment":{"id":1},"deployment_status":{"id":1}}`, "deployment_status", CONFIDENCE_HIGH},
+		{`{"alert":{"number":1,"secret_type":"token"}}`, "secret_scanning_alert", CONFIDENCE_HIGH},
+		{`{"zen":"Keep it logically awesome.","hook_id":1}`, "ping", CONFIDENCE_HIGH},
+		{`{"action":"added","member":{"login":"octocat"}}`, "member", CONFIDENCE_LOW},
+		{`{"action":"published","registry_package":{"id":1}}`, "registry_package", CONFIDENCE_HIGH},
+	}
+
+	for _, test := range tests {
+
+		event, confidence, err := InferEventType([]byte(test.body))
+
+		if err != nil {
+			t.Fatalf("Failed to infer event type for '%s', %v", test.body, err)
+		}
+
+		if event != test.event {
+			t.Fatalf("Unexpected event type for '%s': %s", test.body, event)
+		}
+
+		if confidence != test.confidence {
+			t.Fatalf("Unexpected confidence for '%s': %s", test.body, confidence)
+		}
+	}
+}
+
+func TestInferEventTypeFixtures(t *testing.T) {
+
+	for _, msg := range []string{"fixtures/events/push.json", "fixtures/events/flights.json"} {
+
+		fh, err := os.Open(msg)
+
+		if err != nil {
+			t.Fatalf("Failed to open %s, %v", msg, err)
+		}
+
+		defer fh.Close()
+
+		body, err := io.ReadAll(fh)
+
+		if err != nil {
+			t.Fatalf("Failed to read %s, %v", msg, err)
+		}
+
+		event, confidence, err := InferEventType(body)
+
+		if err != nil {
+			t.Fatalf("Failed to infer event type for %s, %v", msg, err)
+		}
+
+		if event != "push" || confidence != CONFIDENCE_HIGH {
+			t.Fatalf("Unexpected event type for %s: %s (%s)", msg, event, confidence)
+		}
+	}
+}
+
+func TestInferEventTypeAmbiguous(t *testing.T) {
+
+	tests := []string{
+		`{"pages":[],"forkee":{"id":1}}`,
+		`{"issue":{"id":1},"member":{"login":"octocat"}}`,
+		`{"action":"created","repository":{"id":1}}`,
+		`[]`,
+	}
+
+	for _, body := range tests {
+
+		event, confidence, err := InferEventType([]byte(body))
+
+		if err == nil {
+			t.Fatalf("Expected error inferring event type for '%s', got %s (%s)", body, event, confidence)
+		}
+
+		if confidence != CONFIDENCE_NONE {
+			t.Fatalf("Unexpected confidence for '%s': %s", body, confidence)
+		}
+	}
+}
+
+func TestEventSignaturesUnmarshal(t *testing.T) {
+
+	for _, sig := range eventSignatures {
+
+		_, err := UnmarshalEvent(sig.event, []byte(`{}`))
+
+		if err != nil {
+			t.Fatalf("Failed to unmarshal inferable event type %s, %v", sig.event, err)
+		}
+	}
+}
+
+func TestResolveEventTypePing(t *testing.T) {
+
+	body := []byte(`{"zen":"Keep it logically awesome.","hook_id":1}`)
+
+	_, err := resolveEventType(EVENT_INFER, CONFIDENCE_HIGH, body)
+
+	if err == nil || err.Code != webhookd.UnhandledEvent {
+		t.Fatalf("Expected inferred ping event to be unhandled, %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package github
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// ISSUE_SCHEMA is the name of the schema used to encode `issues` and `issue_comment` events as JSON.
+const ISSUE_SCHEMA string = "issue"
+
+// ISSUE_SCHEMA_VERSION is the current version of the `ISSUE_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const ISSUE_SCHEMA_VERSION int = 1
+
+// issueDefaultAssociations is the default list of author associations whose issues and comments are processed by the
+// `GitHubIssuesTransformation`.
+var issueDefaultAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// issueDefaultCommandActions is the default list of actions for which slash-commands are parsed by the `GitHubIssuesTransformation`.
+var issueDefaultCommandActions = []string{"opened", "created"}
+
+// re_command matches a slash-command, for example "/reindex 1713164509", at the start of a line.
+var re_command = regexp.MustCompile(`^/([a-zA-Z][a-zA-Z0-9_\-]*)(?:\s+(.*))?$`)
+
+// IssueCommand is a slash-command parsed from the body of an issue or issue comment.
+type IssueCommand struct {
+	// Name is the name of the command, without the leading slash.
+	Name string `json:"name"`
+	// Args is the list of (whitespace-separated) arguments for the command.
+	Args []string `json:"args"`
+	// Line is the (1-based) line number of the command in the body it was parsed from.
+	Line int `json:"line"`
+}
+
+// IssueRecord is the JSON-encoded representation of a GitHub `issues` or `issue_comment` event produced by transformations in this package.
+type IssueRecord struct {
+	// Schema is the name of the schema for the record. It is always `ISSUE_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event: "issues" or "issue_comment".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event, for example "opened", "labeled" or "created".
+	Action string `json:"action"`
+	// Repo is the name of the repository where the issue was created.
+	Repo string `json:"repo"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository where the issue was created.
+	FullName string `json:"full_name"`
+	// Number is the repository-specific number of the issue.
+	Number int `json:"number"`
+	// Title is the title of the issue.
+	Title string `json:"title"`
+	// State is the state of the issue: "open" or "closed".
+	State string `json:"state"`
+	// PullRequest is a boolean flag indicating whether the issue is a pull request.
+	PullRequest bool `json:"pull_request"`
+	// Labels is the list of labels assigned to the issue.
+	Labels []string `json:"labels"`
+	// Author is the login of the user who wrote the issue, or the comment for `issue_comment` events.
+	Author string `json:"author"`
+	// Association is the relationship of 'Author' to the repository, for example "OWNER", "MEMBER" or "CONTRIBUTOR".
+	Association string `json:"association"`
+	// CommentID is the unique ID of the comment. It is zero for `issues` events.
+	CommentID int64 `json:"comment_id,omitempty"`
+	// HTMLURL is the URL of the (HTML) web page for the issue or comment.
+	HTMLURL string `json:"html_url"`
+	// Commands is the list of slash-commands parsed from the body of the issue, or the comment for `issue_comment` events.
+	Commands []*IssueCommand `json:"commands"`
+}
+
+// parseCommands returns the list of slash-commands in 'body'. A slash-command is a line which starts with "/" followed by
+// the name of the command and zero or more whitespace-separated arguments, for example "/reindex 1713164509". Lines inside
+// fenced code blocks and quoted lines (which start with ">") are ignored.
+func parseCommands(body string) []*IssueCommand {
+
+	commands := make([]*IssueCommand, 0)
+	in_code := false
+
+	for idx, ln := range strings.Split(body, "\n") {
+
+		ln = strings.TrimSpace(ln)
+
+		if strings.HasPrefix(ln, "```") || strings.HasPrefix(ln, "~~~") {
+			in_code = !in_code
+			continue
+		}
+
+		if in_code {
+			continue
+		}
+
+		m := re_command.FindStringSubmatch(ln)
+
+		if m == nil {
+			continue
+		}
+
+		cmd := &IssueCommand{
+			Name: strings.ToLower(m[1]),
+			Args: strings.Fields(m[2]),
+			Line: idx + 1,
+		}
+
+		commands = append(commands, cmd)
+	}
+
+	return commands
+}
+
+// issueLabels returns the names of the labels assigned to 'issue'.
+func issueLabels(issue *gogithub.Issue) []string {
+
+	labels := make([]string, len(issue.Labels))
+
+	for idx, l := range issue.Labels {
+		labels[idx] = l.GetName()
+	}
+
+	return labels
+}
+
+// newIssueRecord returns a new `IssueRecord` instance for the event 'event_type' derived from 'action', 'issue' and 'repo'.
+func newIssueRecord(event_type string, action string, issue *gogithub.Issue, repo *gogithub.Repository) *IssueRecord {
+
+	rec := &IssueRecord{
+		Schema:      ISSUE_SCHEMA,
+		Version:     ISSUE_SCHEMA_VERSION,
+		Event:       event_type,
+		Action:      action,
+		Repo:        repo.GetName(),
+		FullName:    repo.GetFullName(),
+		Number:      issue.GetNumber(),
+		Title:       issue.GetTitle(),
+		State:       issue.GetState(),
+		PullRequest: issue.IsPullRequest(),
+		Labels:      issueLabels(issue),
+		Author:      issue.GetUser().GetLogin(),
+		Association: issue.GetAuthorAssociation(),
+		HTMLURL:     issue.GetHTMLURL(),
+		Commands:    parseCommands(issue.GetBody()),
+	}
+
+	return rec
+}
+
+// newIssueRecordFromIssue returns a new `IssueRecord` instance derived from 'event'.
+func newIssueRecordFromIssue(event *gogithub.IssuesEvent) *IssueRecord {
+	return newIssueRecord("issues", event.GetAction(), event.GetIssue(), event.GetRepo())
+}
+
+// newIssueRecordFromComment returns a new `IssueRecord` instance derived from 'event'. The author, association, URL
+// and commands are those of the comment rather than the issue.
+func newIssueRecordFromComment(event *gogithub.IssueCommentEvent) *IssueRecord {
+
+	comment := event.GetComment()
+
+	rec := newIssueRecord("issue_comment", event.GetAction(), event.GetIssue(), event.GetRepo())
+	rec.Author = comment.GetUser().GetLogin()
+	rec.Association = comment.GetAuthorAssociation()
+	rec.CommentID = comment.GetID()
+	rec.HTMLURL = comment.GetHTMLURL()
+	rec.Commands = parseCommands(comment.GetBody())
+
+	return rec
+}
+
+// issueRuleFieldNames is the list of field names that rules for issue events may be evaluated against.
+var issueRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"title",
+	"state",
+	"pull_request",
+	"label",
+	"author",
+	"association",
+	"command",
+}
+
+// issueRuleFields returns the `RuleFields` for 'rec' used to evaluate rules.
+func issueRuleFields(rec *IssueRecord) RuleFields {
+
+	commands := make([]string, len(rec.Commands))
+
+	for idx, c := range rec.Commands {
+		commands[idx] = c.Name
+	}
+
+	fields := RuleFields{
+		"event":        []string{rec.Event},
+		"action":       []string{rec.Action},
+		"repo":         []string{rec.Repo},
+		"full_name":    []string{rec.FullName},
+		"title":        []string{rec.Title},
+		"state":        []string{rec.State},
+		"pull_request": []string{strconv.FormatBool(rec.PullRequest)},
+		"label":        rec.Labels,
+		"author":       []string{rec.Author},
+		"association":  []string{rec.Association},
+		"command":      commands,
+	}
+
+	return fields
+}
+
+// issueRows returns the CSV rows for 'rec', one for each command (or a single row if there are no commands): the name of the
+// repository, the issue number, the action, the author, the command name and the (space-separated) command arguments.
+func issueRows(rec *IssueRecord) [][]string {
+
+	rows := make([][]string, 0)
+
+	number := strconv.Itoa(rec.Number)
+
+	if len(rec.Commands) == 0 {
+		row := []string{rec.Repo, number, rec.Action, rec.Author, "", ""}
+		rows = append(rows, row)
+	}
+
+	for _, c := range rec.Commands {
+		row := []string{rec.Repo, number, rec.Action, rec.Author, c.Name, strings.Join(c.Args, " ")}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
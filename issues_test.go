@@ -0,0 +1,28 @@
+package github
+
+import (
+	"testing"
+)
+
+func TestParseCommands(t *testing.T) {
+
+	body := "Thanks!\r\n\r\n/reindex 1713164509 1713164511\r\n  /Label verified\r\n/\r\nnot /a-command\r\n\r\n```\r\n/reindex 0\r\n```\r\n\r\n> /close\r\n/close"
+
+	commands := parseCommands(body)
+
+	if len(commands) != 3 {
+		t.Fatalf("Unexpected command count: %d", len(commands))
+	}
+
+	if commands[0].Name != "reindex" || len(commands[0].Args) != 2 || commands[0].Args[1] != "1713164511" || commands[0].Line != 3 {
+		t.Fatalf("Unexpected first command: %v", commands[0])
+	}
+
+	if commands[1].Name != "label" || len(commands[1].Args) != 1 || commands[1].Args[0] != "verified" {
+		t.Fatalf("Unexpected second command: %v", commands[1])
+	}
+
+	if commands[2].Name != "close" || len(commands[2].Args) != 0 || commands[2].Line != 13 {
+		t.Fatalf("Unexpected third command: %v", commands[2])
+	}
+}
@@ -0,0 +1,283 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// LIFECYCLE_SCHEMA is the name of the schema used to encode `repository`, `public`, `fork` and `member` events as JSON.
+const LIFECYCLE_SCHEMA string = "repository_lifecycle"
+
+// LIFECYCLE_SCHEMA_VERSION is the current version of the `LIFECYCLE_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const LIFECYCLE_SCHEMA_VERSION int = 1
+
+// ACTION_PUBLICIZED is the action assigned to `public` events, which do not have an action of their own.
+const ACTION_PUBLICIZED string = "publicized"
+
+// ACTION_FORKED is the action assigned to `fork` events, which do not have an action of their own.
+const ACTION_FORKED string = "forked"
+
+// LifecycleRecord is the JSON-encoded representation of a GitHub `repository`, `public`, `fork` or `member` event produced
+// by transformations in this package.
+type LifecycleRecord struct {
+	// Schema is the name of the schema for the record. It is always `LIFECYCLE_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event: "repository", "public", "fork" or "member".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event, for example "renamed" or "archived". `public` and `fork` events
+	// have the actions `ACTION_PUBLICIZED` and `ACTION_FORKED` respectively.
+	Action string `json:"action"`
+	// Repo is the (current) name of the repository.
+	Repo string `json:"repo"`
+	// FullName is the (current) full name ({OWNER}/{REPO}) of the repository.
+	FullName string `json:"full_name"`
+	// PreviousName is the name of the repository before it was renamed.
+	PreviousName string `json:"previous_name,omitempty"`
+	// Owner is the login of the (current) owner of the repository.
+	Owner string `json:"owner"`
+	// PreviousOwner is the login of the user or organization which owned the repository before it was transferred.
+	PreviousOwner string `json:"previous_owner,omitempty"`
+	// Visibility is the (current) visibility of the repository: "public", "private" or "internal".
+	Visibility string `json:"visibility"`
+	// PreviousVisibility is the visibility of the repository before it was made public or private.
+	PreviousVisibility string `json:"previous_visibility,omitempty"`
+	// Archived is a boolean flag indicating whether the repository is archived.
+	Archived bool `json:"archived"`
+	// Fork is the full name of the fork created by a `fork` event.
+	Fork string `json:"fork,omitempty"`
+	// Member is the login of the collaborator added, removed or edited by a `member` event.
+	Member string `json:"member,omitempty"`
+	// Permission is the permission granted to the collaborator for a `member` event.
+	Permission string `json:"permission,omitempty"`
+	// PreviousPermission is the permission the collaborator had before it was edited.
+	PreviousPermission string `json:"previous_permission,omitempty"`
+	// Changes is a dictionary of the previous values of other properties (for example "default_branch" or "description")
+	// changed by an "edited" action.
+	Changes map[string]string `json:"changes,omitempty"`
+	// Actor is the login of the user who triggered the event.
+	Actor string `json:"actor"`
+}
+
+// lifecycleChange is the previous (and for permissions the new) value of a property in the `changes` field of a webhook message.
+type lifecycleChange struct {
+	From *string `json:"from"`
+	To   *string `json:"to"`
+}
+
+// lifecycleChanges contains the `changes` field of `repository` and `member` webhook messages, most of which are not (yet)
+// defined by the go-github `EditChange` type.
+type lifecycleChanges struct {
+	Changes struct {
+		Repository struct {
+			Name lifecycleChange `json:"name"`
+		} `json:"repository"`
+		Owner struct {
+			From struct {
+				User         *gogithub.User         `json:"user"`
+				Organization *gogithub.Organization `json:"organization"`
+			} `json:"from"`
+		} `json:"owner"`
+		DefaultBranch lifecycleChange `json:"default_branch"`
+		Description   lifecycleChange `json:"description"`
+		Homepage      lifecycleChange `json:"homepage"`
+		Permission    lifecycleChange `json:"permission"`
+		OldPermission lifecycleChange `json:"old_permission"`
+	} `json:"changes"`
+}
+
+// repositoryVisibility returns the visibility of 'repo', deriving it from the `private` property if necessary.
+func repositoryVisibility(repo *gogithub.Repository) string {
+
+	if repo.GetVisibility() != "" {
+		return repo.GetVisibility()
+	}
+
+	if repo.GetPrivate() {
+		return "private"
+	}
+
+	return "public"
+}
+
+// unmarshalLifecycleChanges returns the `lifecycleChanges` for 'body'.
+func unmarshalLifecycleChanges(body []byte) (*lifecycleChanges, error) {
+
+	var changes lifecycleChanges
+
+	err := json.Unmarshal(body, &changes)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal changes, %w", err)
+	}
+
+	return &changes, nil
+}
+
+// newLifecycleRecord returns a new `LifecycleRecord` instance for the event 'event_type' derived from 'action', 'repo' and 'sender'.
+func newLifecycleRecord(event_type string, action string, repo *gogithub.Repository, sender *gogithub.User) *LifecycleRecord {
+
+	rec := &LifecycleRecord{
+		Schema:     LIFECYCLE_SCHEMA,
+		Version:    LIFECYCLE_SCHEMA_VERSION,
+		Event:      event_type,
+		Action:     action,
+		Repo:       repo.GetName(),
+		FullName:   repo.GetFullName(),
+		Owner:      repo.GetOwner().GetLogin(),
+		Visibility: repositoryVisibility(repo),
+		Archived:   repo.GetArchived(),
+		Actor:      sender.GetLogin(),
+	}
+
+	return rec
+}
+
+// newLifecycleRecordFromRepository returns a new `LifecycleRecord` instance derived from 'event' and 'body', the webhook message
+// 'event' was unmarshaled from.
+func newLifecycleRecordFromRepository(event *gogithub.RepositoryEvent, body []byte) (*LifecycleRecord, error) {
+
+	changes, err := unmarshalLifecycleChanges(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rec := newLifecycleRecord("repository", event.GetAction(), event.GetRepo(), event.GetSender())
+
+	if changes.Changes.Repository.Name.From != nil {
+		rec.PreviousName = *changes.Changes.Repository.Name.From
+	}
+
+	switch {
+	case changes.Changes.Owner.From.User != nil:
+		rec.PreviousOwner = changes.Changes.Owner.From.User.GetLogin()
+	case changes.Changes.Owner.From.Organization != nil:
+		rec.PreviousOwner = changes.Changes.Owner.From.Organization.GetLogin()
+	}
+
+	switch rec.Action {
+	case "publicized":
+		rec.PreviousVisibility = "private"
+	case "privatized":
+		rec.PreviousVisibility = "public"
+	}
+
+	other := map[string]lifecycleChange{
+		"default_branch": changes.Changes.DefaultBranch,
+		"description":    changes.Changes.Description,
+		"homepage":       changes.Changes.Homepage,
+	}
+
+	for k, c := range other {
+
+		if c.From == nil {
+			continue
+		}
+
+		if rec.Changes == nil {
+			rec.Changes = make(map[string]string)
+		}
+
+		rec.Changes[k] = *c.From
+	}
+
+	return rec, nil
+}
+
+// newLifecycleRecordFromPublic returns a new `LifecycleRecord` instance derived from 'event'.
+func newLifecycleRecordFromPublic(event *gogithub.PublicEvent) *LifecycleRecord {
+
+	rec := newLifecycleRecord("public", ACTION_PUBLICIZED, event.GetRepo(), event.GetSender())
+	rec.PreviousVisibility = "private"
+
+	return rec
+}
+
+// newLifecycleRecordFromFork returns a new `LifecycleRecord` instance derived from 'event'.
+func newLifecycleRecordFromFork(event *gogithub.ForkEvent) *LifecycleRecord {
+
+	rec := newLifecycleRecord("fork", ACTION_FORKED, event.GetRepo(), event.GetSender())
+	rec.Fork = event.GetForkee().GetFullName()
+
+	return rec
+}
+
+// newLifecycleRecordFromMember returns a new `LifecycleRecord` instance derived from 'event' and 'body', the webhook message
+// 'event' was unmarshaled from.
+func newLifecycleRecordFromMember(event *gogithub.MemberEvent, body []byte) (*LifecycleRecord, error) {
+
+	changes, err := unmarshalLifecycleChanges(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rec := newLifecycleRecord("member", event.GetAction(), event.GetRepo(), event.GetSender())
+	rec.Member = event.GetMember().GetLogin()
+
+	if changes.Changes.Permission.To != nil {
+		rec.Permission = *changes.Changes.Permission.To
+	}
+
+	// Older messages report the previous permission as `old_permission`
+
+	switch {
+	case changes.Changes.Permission.From != nil:
+		rec.PreviousPermission = *changes.Changes.Permission.From
+	case changes.Changes.OldPermission.From != nil:
+		rec.PreviousPermission = *changes.Changes.OldPermission.From
+	}
+
+	return rec, nil
+}
+
+// lifecycleRuleFieldNames is the list of field names that rules for repository lifecycle events may be evaluated against.
+var lifecycleRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"previous_name",
+	"owner",
+	"previous_owner",
+	"visibility",
+	"archived",
+	"member",
+	"permission",
+	"actor",
+}
+
+// lifecycleRuleFields returns the `RuleFields` for 'rec' used to evaluate rules.
+func lifecycleRuleFields(rec *LifecycleRecord) RuleFields {
+
+	fields := RuleFields{
+		"event":          []string{rec.Event},
+		"action":         []string{rec.Action},
+		"repo":           []string{rec.Repo},
+		"full_name":      []string{rec.FullName},
+		"previous_name":  []string{rec.PreviousName},
+		"owner":          []string{rec.Owner},
+		"previous_owner": []string{rec.PreviousOwner},
+		"visibility":     []string{rec.Visibility},
+		"archived":       []string{strconv.FormatBool(rec.Archived)},
+		"member":         []string{rec.Member},
+		"permission":     []string{rec.Permission},
+		"actor":          []string{rec.Actor},
+	}
+
+	return fields
+}
+
+// lifecycleRows returns the CSV rows for 'rec': the name of the repository, the action, the previous name of the repository,
+// the owner, the previous owner, the visibility and the archived state.
+func lifecycleRows(rec *LifecycleRecord) [][]string {
+
+	row := []string{rec.Repo, rec.Action, rec.PreviousName, rec.Owner, rec.PreviousOwner, rec.Visibility, strconv.FormatBool(rec.Archived)}
+	return [][]string{row}
+}
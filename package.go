@@ -0,0 +1,277 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// PACKAGE_SCHEMA is the name of the schema used to encode `package` and `registry_package` events as JSON.
+const PACKAGE_SCHEMA string = "package"
+
+// PACKAGE_SCHEMA_VERSION is the current version of the `PACKAGE_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const PACKAGE_SCHEMA_VERSION int = 1
+
+// CONTAINER_REGISTRY is the host name of the GitHub container registry.
+const CONTAINER_REGISTRY string = "ghcr.io"
+
+// DOCKER_REGISTRY is the host name of the (deprecated) GitHub Docker registry.
+const DOCKER_REGISTRY string = "docker.pkg.github.com"
+
+// PackageRecord is the JSON-encoded representation of a GitHub `package` or `registry_package` event produced by transformations in this package.
+type PackageRecord struct {
+	// Schema is the name of the schema for the record. It is always `PACKAGE_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event: "package" or "registry_package".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event: "published" or "updated".
+	Action string `json:"action"`
+	// Repo is the name of the repository the package belongs to.
+	Repo string `json:"repo,omitempty"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository the package belongs to.
+	FullName string `json:"full_name,omitempty"`
+	// Owner is the login of the user or organization that owns the package.
+	Owner string `json:"owner"`
+	// Name is the name of the package.
+	Name string `json:"name"`
+	// PackageType is the (lower-case) type of the package, for example "container", "docker", "npm", "maven", "rubygems" or "nuget".
+	PackageType string `json:"package_type"`
+	// PackageVersion is the version of the package that was published or updated. For container images it is the image digest.
+	PackageVersion string `json:"package_version"`
+	// Tags is the list of tags for the version of the package. It is only set for container images.
+	Tags []string `json:"tags"`
+	// Reference is the fully qualified reference for the version of the package, for example "ghcr.io/{OWNER}/{NAME}@{DIGEST}"
+	// for container images or "@{OWNER}/{NAME}@{VERSION}" for npm packages.
+	Reference string `json:"reference"`
+	// SHA is the hash of the commit the version of the package was built from.
+	SHA string `json:"sha,omitempty"`
+	// Publisher is the login of the user who published the version of the package.
+	Publisher string `json:"publisher"`
+	// HTMLURL is the URL of the (HTML) web page for the version of the package.
+	HTMLURL string `json:"html_url"`
+}
+
+// packagePayload contains the `package` or `registry_package` property of a webhook message. go-github does not define
+// a type for `registry_package` events and its `PackageMetadata` type can not decode the empty list GitHub sends as the
+// metadata for packages which are not container images, so both events are decoded using this type.
+type packagePayload struct {
+	Name           string                    `json:"name"`
+	PackageType    string                    `json:"package_type"`
+	HTMLURL        string                    `json:"html_url"`
+	Owner          *gogithub.User            `json:"owner"`
+	Registry       *gogithub.PackageRegistry `json:"registry"`
+	PackageVersion struct {
+		Version           string          `json:"version"`
+		TargetOID         string          `json:"target_oid"`
+		HTMLURL           string          `json:"html_url"`
+		Author            *gogithub.User  `json:"author"`
+		Metadata          json.RawMessage `json:"metadata"`
+		ContainerMetadata struct {
+			Tag struct {
+				Name string `json:"name"`
+			} `json:"tag"`
+		} `json:"container_metadata"`
+	} `json:"package_version"`
+}
+
+// packageEvent is a GitHub `package` or `registry_package` webhook message.
+type packageEvent struct {
+	Action          string               `json:"action"`
+	Package         *packagePayload      `json:"package"`
+	RegistryPackage *packagePayload      `json:"registry_package"`
+	Repo            *gogithub.Repository `json:"repository"`
+	Sender          *gogithub.User       `json:"sender"`
+}
+
+// GetAction returns the action of 'e', or an empty string if 'e' is nil.
+func (e *packageEvent) GetAction() string {
+
+	if e == nil {
+		return ""
+	}
+
+	return e.Action
+}
+
+// GetRepo returns the repository of 'e', or nil if 'e' is nil.
+func (e *packageEvent) GetRepo() *gogithub.Repository {
+
+	if e == nil {
+		return nil
+	}
+
+	return e.Repo
+}
+
+// GetSender returns the sender of 'e', or nil if 'e' is nil.
+func (e *packageEvent) GetSender() *gogithub.User {
+
+	if e == nil {
+		return nil
+	}
+
+	return e.Sender
+}
+
+// packageTags returns the list of container image tags for 'pkg'. Tags are read from the `metadata` property of the
+// package version or, for older messages, the `container_metadata` property.
+func packageTags(pkg *packagePayload) []string {
+
+	tags := make([]string, 0)
+
+	var metadata struct {
+		Container struct {
+			Tags []string `json:"tags"`
+		} `json:"container"`
+	}
+
+	// Packages which are not container images have a metadata property of `[]` which fails to unmarshal and is ignored
+
+	err := json.Unmarshal(pkg.PackageVersion.Metadata, &metadata)
+
+	if err == nil {
+		tags = append(tags, metadata.Container.Tags...)
+	}
+
+	if len(tags) == 0 && pkg.PackageVersion.ContainerMetadata.Tag.Name != "" {
+		tags = append(tags, pkg.PackageVersion.ContainerMetadata.Tag.Name)
+	}
+
+	return tags
+}
+
+// packageReference returns the fully qualified reference for version 'version' of the package 'name' of type 'package_type'
+// owned by 'owner'. Container images are referenced by digest if 'version' is a digest. Packages of types other than
+// container, docker and npm are referenced as "{NAME}@{VERSION}".
+func packageReference(package_type string, owner string, repo string, name string, version string, registry_url string) string {
+
+	switch package_type {
+	case "container":
+
+		host := CONTAINER_REGISTRY
+
+		u, err := url.Parse(registry_url)
+
+		if err == nil && u.Host != "" {
+			host = u.Host
+		}
+
+		sep := ":"
+
+		if strings.Contains(version, ":") {
+			sep = "@"
+		}
+
+		return fmt.Sprintf("%s/%s/%s%s%s", host, strings.ToLower(owner), name, sep, version)
+
+	case "docker":
+		return fmt.Sprintf("%s/%s/%s/%s:%s", DOCKER_REGISTRY, strings.ToLower(owner), strings.ToLower(repo), name, version)
+	case "npm":
+		return fmt.Sprintf("@%s/%s@%s", strings.ToLower(owner), name, version)
+	default:
+		return fmt.Sprintf("%s@%s", name, version)
+	}
+}
+
+// newPackageRecord returns a new `PackageRecord` instance for the event 'event_type' derived from 'event'.
+func newPackageRecord(event_type string, event *packageEvent) *PackageRecord {
+
+	pkg := event.Package
+
+	if event_type == "registry_package" {
+		pkg = event.RegistryPackage
+	}
+
+	if pkg == nil {
+		pkg = new(packagePayload)
+	}
+
+	repo := event.Repo
+	version := pkg.PackageVersion
+
+	rec := &PackageRecord{
+		Schema:         PACKAGE_SCHEMA,
+		Version:        PACKAGE_SCHEMA_VERSION,
+		Event:          event_type,
+		Action:         event.Action,
+		Repo:           repo.GetName(),
+		FullName:       repo.GetFullName(),
+		Owner:          pkg.Owner.GetLogin(),
+		Name:           pkg.Name,
+		PackageType:    strings.ToLower(pkg.PackageType),
+		PackageVersion: version.Version,
+		Tags:           packageTags(pkg),
+		SHA:            version.TargetOID,
+		Publisher:      version.Author.GetLogin(),
+		HTMLURL:        version.HTMLURL,
+	}
+
+	rec.Reference = packageReference(rec.PackageType, rec.Owner, rec.Repo, rec.Name, rec.PackageVersion, pkg.Registry.GetURL())
+
+	if rec.Publisher == "" {
+		rec.Publisher = event.Sender.GetLogin()
+	}
+
+	if rec.HTMLURL == "" {
+		rec.HTMLURL = pkg.HTMLURL
+	}
+
+	return rec
+}
+
+// newPackageRecordFromPackage returns a new `PackageRecord` instance derived from the `package` event 'event'.
+func newPackageRecordFromPackage(event *packageEvent) *PackageRecord {
+	return newPackageRecord("package", event)
+}
+
+// newPackageRecordFromRegistryPackage returns a new `PackageRecord` instance derived from the `registry_package` event 'event'.
+func newPackageRecordFromRegistryPackage(event *packageEvent) *PackageRecord {
+	return newPackageRecord("registry_package", event)
+}
+
+// packageRuleFieldNames is the list of field names that rules for package events may be evaluated against.
+var packageRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"owner",
+	"name",
+	"package_type",
+	"package_version",
+	"tag",
+	"publisher",
+}
+
+// packageRuleFields returns the `RuleFields` for 'rec' used to evaluate rules. The "tag" field contains one value for each tag.
+func packageRuleFields(rec *PackageRecord) RuleFields {
+
+	fields := RuleFields{
+		"event":           []string{rec.Event},
+		"action":          []string{rec.Action},
+		"repo":            []string{rec.Repo},
+		"full_name":       []string{rec.FullName},
+		"owner":           []string{rec.Owner},
+		"name":            []string{rec.Name},
+		"package_type":    []string{rec.PackageType},
+		"package_version": []string{rec.PackageVersion},
+		"tag":             rec.Tags,
+		"publisher":       []string{rec.Publisher},
+	}
+
+	return fields
+}
+
+// packageRows returns the CSV rows for 'rec': the name of the repository, the name of the event, the action, the package type,
+// the package name, the package version, the (space-separated) tags and the package reference.
+func packageRows(rec *PackageRecord) [][]string {
+
+	row := []string{rec.Repo, rec.Event, rec.Action, rec.PackageType, rec.Name, rec.PackageVersion, strings.Join(rec.Tags, " "), rec.Reference}
+	return [][]string{row}
+}
@@ -0,0 +1,30 @@
+package github
+
+import (
+	"testing"
+)
+
+func TestPackageReference(t *testing.T) {
+
+	tests := []struct {
+		package_type string
+		version      string
+		registry_url string
+		expected     string
+	}{
+		{"container", "sha256:9b1f", "https://ghcr.io", "ghcr.io/sfomuseum-data/flights@sha256:9b1f"},
+		{"container", "2020-05", "", "ghcr.io/sfomuseum-data/flights:2020-05"},
+		{"docker", "1.4.0", "", "docker.pkg.github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/flights:1.4.0"},
+		{"npm", "1.4.0", "https://npm.pkg.github.com/sfomuseum-data", "@sfomuseum-data/flights@1.4.0"},
+		{"maven", "1.4.0", "", "flights@1.4.0"},
+	}
+
+	for _, test := range tests {
+
+		ref := packageReference(test.package_type, "sfomuseum-data", "sfomuseum-data-flights-2020-05", "flights", test.version, test.registry_url)
+
+		if ref != test.expected {
+			t.Fatalf("Unexpected reference for %s: %s", test.package_type, ref)
+		}
+	}
+}
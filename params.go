@@ -0,0 +1,149 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// parseBoolParams assigns the boolean value of each query parameter in 'q' whose name is a key in 'params'
+// to its corresponding pointer. Parameters that are absent or empty are left unchanged.
+func parseBoolParams(q url.Values, params map[string]*bool) error {
+
+	for k, ptr := range params {
+
+		str_v := q.Get(k)
+
+		if str_v == "" {
+			continue
+		}
+
+		v, err := strconv.ParseBool(str_v)
+
+		if err != nil {
+			return fmt.Errorf("Failed to parse ?%s= parameter, %w", k, err)
+		}
+
+		*ptr = v
+	}
+
+	return nil
+}
+
+// parseListParam returns the list of (non-empty) values for the query parameter 'k' in 'q'. Parameters may be
+// specified multiple times and each value may be a comma-separated list.
+func parseListParam(q url.Values, k string) []string {
+
+	values := make([]string, 0)
+
+	for _, str_v := range q[k] {
+
+		for _, v := range strings.Split(str_v, ",") {
+
+			v = strings.TrimSpace(v)
+
+			if v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+
+	return values
+}
+
+// matchesPattern returns a boolean value indicating whether 'name' (for example a branch or tag name) matches any of
+// 'patterns', which are `path.Match` patterns (see `matchGlob`). If 'patterns' is empty every name matches.
+func matchesPattern(name string, patterns []string) bool {
+
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, p := range patterns {
+
+		if matchGlob(p, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesFilters returns a boolean value indicating whether 'name' (for example a file path or a wiki page name) matches
+// any of the patterns in 'include' (or 'include' is empty) and none of the patterns in 'exclude'.
+func matchesFilters(name string, include []string, exclude []string) bool {
+
+	if !matchesPattern(name, include) {
+		return false
+	}
+
+	return len(exclude) == 0 || !matchesPattern(name, exclude)
+}
+
+// filterActions returns the subset of 'items' whose action, as returned by 'action', is not flagged in 'exclude'. For
+// example the map `{"added": true}` would omit all the items whose action is "added".
+func filterActions[T any](items []T, action func(T) string, exclude map[string]bool) []T {
+
+	filtered := make([]T, 0)
+
+	for _, i := range items {
+
+		if exclude[action(i)] {
+			continue
+		}
+
+		filtered = append(filtered, i)
+	}
+
+	return filtered
+}
+
+// matchGlob returns a boolean value indicating whether 'name' matches 'pattern', which is a `path.Match` pattern with the
+// addition that a "**" path segment will match zero or more path segments. For example "data/*" will match "data/a.geojson"
+// but not "data/a/b.geojson" whereas "data/**" or "data/**/*.geojson" will match both.
+func matchGlob(pattern string, name string) bool {
+
+	if !strings.Contains(pattern, "**") {
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments returns a boolean value indicating whether the path segments in 'name' match the `path.Match` patterns in
+// 'pattern', where a "**" pattern matches zero or more segments.
+func matchSegments(pattern []string, name []string) bool {
+
+	for len(pattern) > 0 {
+
+		if pattern[0] == "**" {
+
+			for i := 0; i <= len(name); i++ {
+
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		ok, _ := path.Match(pattern[0], name[0])
+
+		if !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0
+}
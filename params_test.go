@@ -0,0 +1,29 @@
+package github
+
+import (
+	"testing"
+)
+
+func TestMatchesFilters(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected bool
+	}{
+		{"Data-Sources", nil, nil, true},
+		{"Data-Sources", []string{"Data-*"}, nil, true},
+		{"Home", []string{"Data-*"}, nil, false},
+		{"_Sidebar", nil, []string{"_*"}, false},
+		{"Data-Sources", []string{"Data-*"}, []string{"*-Sources"}, false},
+		{"data/a/b.geojson", []string{"data/**"}, []string{"data/**/*.csv"}, true},
+	}
+
+	for _, test := range tests {
+
+		if matchesFilters(test.name, test.include, test.exclude) != test.expected {
+			t.Fatalf("Unexpected result for '%s' (include %v, exclude %v)", test.name, test.include, test.exclude)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// PULL_REQUEST_SCHEMA is the name of the schema used to encode `pull_request` events as JSON.
+const PULL_REQUEST_SCHEMA string = "pull_request"
+
+// PULL_REQUEST_SCHEMA_VERSION is the current version of the `PULL_REQUEST_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const PULL_REQUEST_SCHEMA_VERSION int = 1
+
+// ACTION_MERGED is the pseudo-action used to filter `pull_request` events whose action is "closed" and whose pull request was merged.
+const ACTION_MERGED string = "merged"
+
+// PullRequestFile is a single file changed by a pull request, as reported by the GitHub API.
+type PullRequestFile struct {
+	// Path is the path of the file, relative to the root of the repository.
+	Path string `json:"path"`
+	// Status is the kind of change, for example "added", "modified", "removed" or "renamed".
+	Status string `json:"status"`
+	// PreviousPath is the path of the file before it was renamed.
+	PreviousPath string `json:"previous_path,omitempty"`
+	// Additions is the number of lines added to the file.
+	Additions int `json:"additions"`
+	// Deletions is the number of lines removed from the file.
+	Deletions int `json:"deletions"`
+}
+
+// PullRequestRecord is the JSON-encoded representation of a GitHub `pull_request` event produced by transformations in this package.
+type PullRequestRecord struct {
+	// Schema is the name of the schema for the record. It is always `PULL_REQUEST_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Action is the activity that triggered the event, for example "opened" or "synchronize".
+	Action string `json:"action"`
+	// Repo is the name of the repository the pull request was opened against.
+	Repo string `json:"repo"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository the pull request was opened against.
+	FullName string `json:"full_name"`
+	// Number is the pull request number.
+	Number int `json:"number"`
+	// Title is the title of the pull request.
+	Title string `json:"title"`
+	// Author is the login of the user who opened the pull request.
+	Author string `json:"author"`
+	// State is the state of the pull request, "open" or "closed".
+	State string `json:"state"`
+	// Draft is a boolean flag indicating whether the pull request is a draft.
+	Draft bool `json:"draft"`
+	// Merged is a boolean flag indicating whether the pull request has been merged.
+	Merged bool `json:"merged"`
+	// MergeCommit is the hash of the merge commit, if the pull request has been merged.
+	MergeCommit string `json:"merge_commit,omitempty"`
+	// Base is the name of the branch the pull request will be merged in to.
+	Base string `json:"base"`
+	// BaseSHA is the hash of the most recent commit on 'Base'.
+	BaseSHA string `json:"base_sha"`
+	// Head is the name of the branch containing the changes in the pull request.
+	Head string `json:"head"`
+	// SHA is the hash of the most recent commit on 'Head'.
+	SHA string `json:"sha"`
+	// Labels is the list of labels assigned to the pull request.
+	Labels []string `json:"labels"`
+	// HTMLURL is the URL of the (HTML) web page for the pull request.
+	HTMLURL string `json:"html_url"`
+	// Commits is the number of commits in the pull request.
+	Commits int `json:"commits"`
+	// Additions is the number of lines added by the pull request.
+	Additions int `json:"additions"`
+	// Deletions is the number of lines removed by the pull request.
+	Deletions int `json:"deletions"`
+	// ChangedFiles is the number of files changed by the pull request.
+	ChangedFiles int `json:"changed_files"`
+	// Files is the list of files changed by the pull request. It is only set if the list has been retrieved from the GitHub API.
+	Files []*PullRequestFile `json:"files,omitempty"`
+	// sender is the login of the user who triggered the event.
+	sender string
+}
+
+// pullRequestLabels returns the names of the labels assigned to 'pr'.
+func pullRequestLabels(pr *gogithub.PullRequest) []string {
+
+	labels := make([]string, len(pr.Labels))
+
+	for idx, l := range pr.Labels {
+		labels[idx] = l.GetName()
+	}
+
+	return labels
+}
+
+// newPullRequestRecord returns a new `PullRequestRecord` instance derived from 'event'.
+func newPullRequestRecord(event *gogithub.PullRequestEvent) *PullRequestRecord {
+
+	pr := event.GetPullRequest()
+	repo := event.GetRepo()
+
+	rec := &PullRequestRecord{
+		Schema:       PULL_REQUEST_SCHEMA,
+		Version:      PULL_REQUEST_SCHEMA_VERSION,
+		Action:       event.GetAction(),
+		Repo:         repo.GetName(),
+		FullName:     repo.GetFullName(),
+		Number:       pr.GetNumber(),
+		Title:        pr.GetTitle(),
+		Author:       pr.GetUser().GetLogin(),
+		State:        pr.GetState(),
+		Draft:        pr.GetDraft(),
+		Merged:       pr.GetMerged(),
+		Base:         pr.GetBase().GetRef(),
+		BaseSHA:      pr.GetBase().GetSHA(),
+		Head:         pr.GetHead().GetRef(),
+		SHA:          pr.GetHead().GetSHA(),
+		Labels:       pullRequestLabels(pr),
+		HTMLURL:      pr.GetHTMLURL(),
+		Commits:      pr.GetCommits(),
+		Additions:    pr.GetAdditions(),
+		Deletions:    pr.GetDeletions(),
+		ChangedFiles: pr.GetChangedFiles(),
+		sender:       event.GetSender().GetLogin(),
+	}
+
+	if rec.Merged {
+		rec.MergeCommit = pr.GetMergeCommitSHA()
+	}
+
+	return rec
+}
+
+// matchesAction returns a boolean value indicating whether 'rec' matches any of 'actions'. The `ACTION_MERGED`
+// pseudo-action matches "closed" events whose pull request was merged. If 'actions' is empty every event matches.
+func matchesAction(rec *PullRequestRecord, actions []string) bool {
+
+	if len(actions) == 0 {
+		return true
+	}
+
+	for _, a := range actions {
+
+		if a == rec.Action {
+			return true
+		}
+
+		if a == ACTION_MERGED && rec.Action == "closed" && rec.Merged {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasAnyLabel returns a boolean value indicating whether any of 'labels' are in 'candidates'.
+func hasAnyLabel(labels []string, candidates []string) bool {
+
+	for _, l := range labels {
+
+		for _, c := range candidates {
+
+			if l == c {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// listPullRequestFiles returns the list of files changed by pull request 'number' in 'owner'/'repo', retrieving every
+// page of results from the GitHub API.
+func listPullRequestFiles(ctx context.Context, client *gogithub.Client, owner string, repo string, number int) ([]*PullRequestFile, error) {
+
+	files := make([]*PullRequestFile, 0)
+
+	opts := &gogithub.ListOptions{
+		PerPage: 100,
+	}
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			// pass
+		}
+
+		page, rsp, err := client.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list files for pull request %d, %w", number, err)
+		}
+
+		for _, f := range page {
+
+			files = append(files, &PullRequestFile{
+				Path:         f.GetFilename(),
+				Status:       f.GetStatus(),
+				PreviousPath: f.GetPreviousFilename(),
+				Additions:    f.GetAdditions(),
+				Deletions:    f.GetDeletions(),
+			})
+		}
+
+		if rsp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = rsp.NextPage
+	}
+
+	return files, nil
+}
+
+// pullRequestRuleFieldNames is the list of field names that rules for `pull_request` events may be evaluated against.
+var pullRequestRuleFieldNames = []string{
+	"action",
+	"repo",
+	"full_name",
+	"number",
+	"title",
+	"author",
+	"sender",
+	"base",
+	"head",
+	"draft",
+	"merged",
+	"label",
+	"path",
+}
+
+// pullRequestFileRuleFieldNames is the list of field names for `pull_request` events whose values are only known once the list of
+// files changed by a pull request has been retrieved from the GitHub API.
+var pullRequestFileRuleFieldNames = []string{
+	"path",
+}
+
+// pullRequestRuleFields returns the `RuleFields` for 'rec' used to evaluate rules. The `path` field is only populated
+// if the list of files has been retrieved from the GitHub API.
+func pullRequestRuleFields(rec *PullRequestRecord) RuleFields {
+
+	paths := make([]string, len(rec.Files))
+
+	for idx, f := range rec.Files {
+		paths[idx] = f.Path
+	}
+
+	fields := RuleFields{
+		"action":    []string{rec.Action},
+		"repo":      []string{rec.Repo},
+		"full_name": []string{rec.FullName},
+		"number":    []string{strconv.Itoa(rec.Number)},
+		"title":     []string{rec.Title},
+		"author":    []string{rec.Author},
+		"sender":    []string{rec.sender},
+		"base":      []string{rec.Base},
+		"head":      []string{rec.Head},
+		"draft":     []string{strconv.FormatBool(rec.Draft)},
+		"merged":    []string{strconv.FormatBool(rec.Merged)},
+		"label":     rec.Labels,
+		"path":      paths,
+	}
+
+	return fields
+}
+
+// pullRequestRows returns the CSV rows for 'rec': the name of the repository, the pull request number, the action, the base
+// branch, the head branch and the head commit hash. If the list of files changed by the pull request has been retrieved from
+// the GitHub API there is instead one row for each file: the name of the repository, the pull request number, the head commit
+// hash, the status of the file and its path.
+func pullRequestRows(rec *PullRequestRecord) [][]string {
+
+	number := strconv.Itoa(rec.Number)
+
+	if rec.Files == nil {
+		row := []string{rec.Repo, number, rec.Action, rec.Base, rec.Head, rec.SHA}
+		return [][]string{row}
+	}
+
+	rows := make([][]string, 0)
+
+	for _, f := range rec.Files {
+		row := []string{rec.Repo, number, rec.SHA, f.Status, f.Path}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
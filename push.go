@@ -0,0 +1,485 @@
+package github
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// PUSH_SCHEMA is the name of the schema used to encode `push` events as JSON.
+const PUSH_SCHEMA string = "push"
+
+// PUSH_SCHEMA_VERSION is the current version of the `PUSH_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const PUSH_SCHEMA_VERSION int = 1
+
+// ACTION_ADDED is the action assigned to files that were added in a commit.
+const ACTION_ADDED string = "added"
+
+// ACTION_MODIFIED is the action assigned to files that were modified in a commit.
+const ACTION_MODIFIED string = "modified"
+
+// ACTION_REMOVED is the action assigned to files that were removed in a commit.
+const ACTION_REMOVED string = "removed"
+
+// ACTION_RENAMED is the action assigned to files that were renamed (moved) in a commit.
+const ACTION_RENAMED string = "renamed"
+
+// Change is a single file change derived from a GitHub `push` event.
+type Change struct {
+	// Action is the kind of change: `added`, `modified`, `removed` or `renamed`.
+	Action string `json:"action"`
+	// Commit is the hash of the commit in which the change occurred.
+	Commit string `json:"commit"`
+	// Repo is the name of the repository where the change occurred.
+	Repo string `json:"repo"`
+	// Path is the path of the file that was changed, relative to the root of the repository.
+	Path string `json:"path"`
+	// PreviousPath is the path of the file before it was renamed. It is only set for changes whose action is `renamed`.
+	PreviousPath string `json:"previous_path,omitempty"`
+	// FileURLs is the optional set of URLs for the file at 'Commit'.
+	*FileURLs
+	// Trailers is the list of Git trailers in the message for 'Commit'.
+	Trailers []*Trailer `json:"trailers,omitempty"`
+	// commit is the commit in which the change occurred, if known.
+	commit *gogithub.HeadCommit
+}
+
+// CommitRecord is the JSON-encoded representation of a single commit in a GitHub `push` event.
+type CommitRecord struct {
+	// ID is the hash of the commit.
+	ID string `json:"id"`
+	// Message is the commit message.
+	Message string `json:"message"`
+	// Author is the name of the author of the commit.
+	Author string `json:"author"`
+	// AuthorEmail is the email address of the author of the commit.
+	AuthorEmail string `json:"author_email"`
+	// Trailers is the list of Git trailers in the commit message.
+	Trailers []*Trailer `json:"trailers"`
+	// CoAuthors is the list of co-authors derived from the 'Co-authored-by:' trailers in the commit message.
+	CoAuthors []*CoAuthor `json:"co_authors"`
+}
+
+// PushRecord is the JSON-encoded representation of a GitHub `push` event produced by transformations in this package.
+type PushRecord struct {
+	// Schema is the name of the schema for the record. It is always `PUSH_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Repo is the name of the repository that was pushed to.
+	Repo string `json:"repo"`
+	// Ref is the Git reference (branch) that was pushed to.
+	Ref string `json:"ref"`
+	// Before is the hash of the most recent commit on 'Ref' before the push.
+	Before string `json:"before"`
+	// After is the hash of the most recent commit on 'Ref' after the push.
+	After string `json:"after"`
+	// Commit is the hash of the head commit for the push.
+	Commit string `json:"commit"`
+	// Message is the message for the head commit.
+	Message string `json:"message"`
+	// Author is the name of the author of the head commit.
+	Author string `json:"author"`
+	// Commits is the list of commits included in the push.
+	Commits []*CommitRecord `json:"commits"`
+	// Changes is the list of file changes included in the push.
+	Changes []*Change `json:"changes"`
+}
+
+// pushChanges returns the list of file changes in 'event', in commit order.
+func pushChanges(event *gogithub.PushEvent) []*Change {
+
+	repo_name := event.GetRepo().GetName()
+	changes := make([]*Change, 0)
+
+	for _, c := range event.Commits {
+
+		commit_hash := c.GetID()
+		trailers := parseTrailers(c.GetMessage())
+
+		for _, path := range c.Added {
+			changes = append(changes, &Change{Action: ACTION_ADDED, Commit: commit_hash, Repo: repo_name, Path: path, Trailers: trailers, commit: c})
+		}
+
+		for _, path := range c.Modified {
+			changes = append(changes, &Change{Action: ACTION_MODIFIED, Commit: commit_hash, Repo: repo_name, Path: path, Trailers: trailers, commit: c})
+		}
+
+		for _, path := range c.Removed {
+			changes = append(changes, &Change{Action: ACTION_REMOVED, Commit: commit_hash, Repo: repo_name, Path: path, Trailers: trailers, commit: c})
+		}
+	}
+
+	return changes
+}
+
+// filterChanges returns the subset of 'changes' omitting additions, modifications, deletions or renames as specified.
+func filterChanges(changes []*Change, exclude_additions bool, exclude_modifications bool, exclude_deletions bool, exclude_renames bool) []*Change {
+
+	exclude := map[string]bool{
+		ACTION_ADDED:    exclude_additions,
+		ACTION_MODIFIED: exclude_modifications,
+		ACTION_REMOVED:  exclude_deletions,
+		ACTION_RENAMED:  exclude_renames,
+	}
+
+	return filterActions(changes, func(ch *Change) string { return ch.Action }, exclude)
+}
+
+// pairRenames returns a copy of 'changes' where files that were removed and added in the same commit with identical
+// base names are replaced by a single change whose action is `renamed`. Pairs are only made when there is exactly one
+// candidate addition and one candidate removal for a given commit and base name.
+func pairRenames(changes []*Change) []*Change {
+
+	added := make(map[string][]int)
+	removed := make(map[string][]int)
+
+	for idx, ch := range changes {
+
+		k := fmt.Sprintf("%s#%s", ch.Commit, path.Base(ch.Path))
+
+		switch ch.Action {
+		case ACTION_ADDED:
+			added[k] = append(added[k], idx)
+		case ACTION_REMOVED:
+			removed[k] = append(removed[k], idx)
+		}
+	}
+
+	renamed := make(map[int]string)
+	skip := make(map[int]bool)
+
+	for k, a := range added {
+
+		r, ok := removed[k]
+
+		if !ok || len(a) != 1 || len(r) != 1 {
+			continue
+		}
+
+		renamed[a[0]] = changes[r[0]].Path
+		skip[r[0]] = true
+	}
+
+	paired := make([]*Change, 0)
+
+	for idx, ch := range changes {
+
+		if skip[idx] {
+			continue
+		}
+
+		previous_path, ok := renamed[idx]
+
+		if ok {
+
+			ch = &Change{
+				Action:       ACTION_RENAMED,
+				Commit:       ch.Commit,
+				Repo:         ch.Repo,
+				Path:         ch.Path,
+				PreviousPath: previous_path,
+				Trailers:     ch.Trailers,
+				commit:       ch.commit,
+			}
+		}
+
+		paired = append(paired, ch)
+	}
+
+	return paired
+}
+
+// newPushRecord returns a new `PushRecord` instance derived from 'event' and 'changes'.
+func newPushRecord(event *gogithub.PushEvent, changes []*Change) *PushRecord {
+
+	head := event.GetHeadCommit()
+
+	rec := &PushRecord{
+		Schema:  PUSH_SCHEMA,
+		Version: PUSH_SCHEMA_VERSION,
+		Repo:    event.GetRepo().GetName(),
+		Ref:     event.GetRef(),
+		Before:  event.GetBefore(),
+		After:   event.GetAfter(),
+		Commit:  head.GetID(),
+		Message: head.GetMessage(),
+		Author:  head.GetAuthor().GetName(),
+		Commits: make([]*CommitRecord, len(event.Commits)),
+		Changes: changes,
+	}
+
+	for idx, c := range event.Commits {
+
+		trailers := parseTrailers(c.GetMessage())
+
+		rec.Commits[idx] = &CommitRecord{
+			ID:          c.GetID(),
+			Message:     c.GetMessage(),
+			Author:      c.GetAuthor().GetName(),
+			AuthorEmail: c.GetAuthor().GetEmail(),
+			Trailers:    trailers,
+			CoAuthors:   parseCoAuthors(trailers),
+		}
+	}
+
+	return rec
+}
+
+// REPO_SCHEMA is the name of the schema used to encode the repository associated with a `push` event as JSON.
+const REPO_SCHEMA string = "repo"
+
+// REPO_SCHEMA_VERSION is the current version of the `REPO_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const REPO_SCHEMA_VERSION int = 1
+
+// RepoRecord is the JSON-encoded representation of the repository associated with a GitHub `push` event
+// produced by the `GitHubRepoTransformation` transformation.
+type RepoRecord struct {
+	// Schema is the name of the schema for the record. It is always `REPO_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Repo is the name of the repository that was pushed to.
+	Repo string `json:"repo"`
+	// FullName is the full name of the repository that was pushed to, including its owner.
+	FullName string `json:"full_name"`
+	// Ref is the Git reference that was pushed to.
+	Ref string `json:"ref"`
+	// Branch is the name of the branch that was pushed to, derived from 'Ref'.
+	Branch string `json:"branch"`
+	// Before is the hash of the most recent commit on 'Ref' before the push.
+	Before string `json:"before"`
+	// After is the hash of the most recent commit on 'Ref' after the push.
+	After string `json:"after"`
+	// Added is the number of files added in the push.
+	Added int `json:"added"`
+	// Modified is the number of files modified in the push.
+	Modified int `json:"modified"`
+	// Removed is the number of files removed in the push.
+	Removed int `json:"removed"`
+	// Message is the message for the head commit.
+	Message string `json:"message"`
+	// Author is the name of the author of the head commit.
+	Author string `json:"author"`
+}
+
+// REPO_FIELD_NAME is the name of the `RepoRecord` field containing the name of the repository.
+const REPO_FIELD_NAME string = "name"
+
+// REPO_FIELD_FULL_NAME is the name of the `RepoRecord` field containing the full name of the repository.
+const REPO_FIELD_FULL_NAME string = "full_name"
+
+// REPO_FIELD_REF is the name of the `RepoRecord` field containing the Git reference that was pushed to.
+const REPO_FIELD_REF string = "ref"
+
+// REPO_FIELD_BRANCH is the name of the `RepoRecord` field containing the branch that was pushed to.
+const REPO_FIELD_BRANCH string = "branch"
+
+// REPO_FIELD_BEFORE is the name of the `RepoRecord` field containing the hash of the most recent commit before the push.
+const REPO_FIELD_BEFORE string = "before"
+
+// REPO_FIELD_AFTER is the name of the `RepoRecord` field containing the hash of the most recent commit after the push.
+const REPO_FIELD_AFTER string = "after"
+
+// REPO_FIELD_ADDED is the name of the `RepoRecord` field containing the number of files added in the push.
+const REPO_FIELD_ADDED string = "added"
+
+// REPO_FIELD_MODIFIED is the name of the `RepoRecord` field containing the number of files modified in the push.
+const REPO_FIELD_MODIFIED string = "modified"
+
+// REPO_FIELD_REMOVED is the name of the `RepoRecord` field containing the number of files removed in the push.
+const REPO_FIELD_REMOVED string = "removed"
+
+// parseRepoFields returns the list of valid `RepoRecord` field names in 'str', a comma-separated list.
+func parseRepoFields(str string) ([]string, error) {
+
+	fields := make([]string, 0)
+
+	for _, f := range strings.Split(str, ",") {
+
+		f = strings.TrimSpace(f)
+
+		switch f {
+		case "":
+			continue
+		case REPO_FIELD_NAME, REPO_FIELD_FULL_NAME, REPO_FIELD_REF, REPO_FIELD_BRANCH, REPO_FIELD_BEFORE, REPO_FIELD_AFTER, REPO_FIELD_ADDED, REPO_FIELD_MODIFIED, REPO_FIELD_REMOVED:
+			fields = append(fields, f)
+		default:
+			return nil, fmt.Errorf("Invalid field '%s'", f)
+		}
+	}
+
+	return fields, nil
+}
+
+// Values returns the string values of 'fields' for 'rec'.
+func (rec *RepoRecord) Values(fields []string) []string {
+
+	values := make([]string, len(fields))
+
+	for idx, f := range fields {
+
+		switch f {
+		case REPO_FIELD_NAME:
+			values[idx] = rec.Repo
+		case REPO_FIELD_FULL_NAME:
+			values[idx] = rec.FullName
+		case REPO_FIELD_REF:
+			values[idx] = rec.Ref
+		case REPO_FIELD_BRANCH:
+			values[idx] = rec.Branch
+		case REPO_FIELD_BEFORE:
+			values[idx] = rec.Before
+		case REPO_FIELD_AFTER:
+			values[idx] = rec.After
+		case REPO_FIELD_ADDED:
+			values[idx] = strconv.Itoa(rec.Added)
+		case REPO_FIELD_MODIFIED:
+			values[idx] = strconv.Itoa(rec.Modified)
+		case REPO_FIELD_REMOVED:
+			values[idx] = strconv.Itoa(rec.Removed)
+		}
+	}
+
+	return values
+}
+
+// newRepoRecord returns a new `RepoRecord` instance derived from 'event' whose added, modified and removed counts are derived from 'changes'.
+func newRepoRecord(event *gogithub.PushEvent, changes []*Change) *RepoRecord {
+
+	head := event.GetHeadCommit()
+
+	rec := &RepoRecord{
+		Schema:   REPO_SCHEMA,
+		Version:  REPO_SCHEMA_VERSION,
+		Repo:     event.GetRepo().GetName(),
+		FullName: event.GetRepo().GetFullName(),
+		Ref:      event.GetRef(),
+		Branch:   strings.TrimPrefix(event.GetRef(), "refs/heads/"),
+		Before:   event.GetBefore(),
+		After:    event.GetAfter(),
+		Message:  head.GetMessage(),
+		Author:   head.GetAuthor().GetName(),
+	}
+
+	for _, ch := range changes {
+
+		switch ch.Action {
+		case ACTION_ADDED:
+			rec.Added += 1
+		case ACTION_MODIFIED:
+			rec.Modified += 1
+		case ACTION_REMOVED:
+			rec.Removed += 1
+		}
+	}
+
+	return rec
+}
+
+// pushRuleFieldNames is the list of field names that rules can be evaluated against for `push` events.
+var pushRuleFieldNames = []string{
+	"ref",
+	"branch",
+	"repo",
+	"full_name",
+	"sender",
+	"pusher",
+	"message",
+	"head_message",
+	"author",
+	"head_author",
+	"author_email",
+	"committer",
+	"committer_email",
+	"path",
+	"added",
+	"modified",
+	"removed",
+	"renamed",
+	"changes",
+	"co_author",
+	"co_author_email",
+	"trailer.*",
+}
+
+// pushChangeRuleFieldNames is the subset of `pushRuleFieldNames` whose values are derived from the list of changed files.
+var pushChangeRuleFieldNames = []string{
+	"path",
+	"added",
+	"modified",
+	"removed",
+	"renamed",
+	"changes",
+}
+
+// pushRuleFields returns the `RuleFields` for 'event' and 'changes'. Fields derived from commits (for example "message")
+// contain values for every commit in 'event'. Git trailers are assigned to fields named "trailer.{KEY}" where {KEY} is the
+// lower-cased name of the trailer. The "path" field contains the path (and previous path) for every
+// element in 'changes'.
+func pushRuleFields(event *gogithub.PushEvent, changes []*Change) RuleFields {
+
+	head := event.GetHeadCommit()
+
+	fields := RuleFields{
+		"ref":          []string{event.GetRef()},
+		"branch":       []string{strings.TrimPrefix(event.GetRef(), "refs/heads/")},
+		"repo":         []string{event.GetRepo().GetName()},
+		"full_name":    []string{event.GetRepo().GetFullName()},
+		"sender":       []string{event.GetSender().GetLogin()},
+		"pusher":       []string{event.GetPusher().GetName()},
+		"head_message": []string{head.GetMessage()},
+		"head_author":  []string{head.GetAuthor().GetName()},
+	}
+
+	for _, c := range event.Commits {
+		fields["message"] = append(fields["message"], c.GetMessage())
+		fields["author"] = append(fields["author"], c.GetAuthor().GetName())
+		fields["author_email"] = append(fields["author_email"], c.GetAuthor().GetEmail())
+		fields["committer"] = append(fields["committer"], c.GetCommitter().GetName())
+		fields["committer_email"] = append(fields["committer_email"], c.GetCommitter().GetEmail())
+
+		trailers := parseTrailers(c.GetMessage())
+
+		for _, t := range trailers {
+			k := fmt.Sprintf("trailer.%s", strings.ToLower(t.Key))
+			fields[k] = append(fields[k], t.Value)
+		}
+
+		for _, a := range parseCoAuthors(trailers) {
+			fields["co_author"] = append(fields["co_author"], a.Name)
+			fields["co_author_email"] = append(fields["co_author_email"], a.Email)
+		}
+	}
+
+	counts := map[string]int{
+		ACTION_ADDED:    0,
+		ACTION_MODIFIED: 0,
+		ACTION_REMOVED:  0,
+		ACTION_RENAMED:  0,
+	}
+
+	for _, ch := range changes {
+
+		fields["path"] = append(fields["path"], ch.Path)
+
+		if ch.PreviousPath != "" {
+			fields["path"] = append(fields["path"], ch.PreviousPath)
+		}
+
+		counts[ch.Action] += 1
+	}
+
+	for action, count := range counts {
+		fields[action] = []string{strconv.Itoa(count)}
+	}
+
+	fields["changes"] = []string{strconv.Itoa(len(changes))}
+
+	return fields
+}
@@ -0,0 +1,204 @@
+package github
+
+import (
+	"strconv"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// RELEASE_SCHEMA is the name of the schema used to encode `release`, `create` and `delete` (tag) events as JSON.
+const RELEASE_SCHEMA string = "release"
+
+// RELEASE_SCHEMA_VERSION is the current version of the `RELEASE_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const RELEASE_SCHEMA_VERSION int = 1
+
+// REF_TYPE_TAG is the `ref_type` of `create` and `delete` events for tags.
+const REF_TYPE_TAG string = "tag"
+
+// ReleaseAsset is a single file attached to a GitHub release.
+type ReleaseAsset struct {
+	// Name is the filename of the asset.
+	Name string `json:"name"`
+	// ContentType is the content type of the asset.
+	ContentType string `json:"content_type,omitempty"`
+	// Size is the size of the asset, in bytes.
+	Size int `json:"size"`
+	// DownloadURL is the URL used to download the asset.
+	DownloadURL string `json:"download_url"`
+}
+
+// ReleaseRecord is the JSON-encoded representation of a GitHub `release` event, or `create` and `delete` events for tags,
+// produced by transformations in this package.
+type ReleaseRecord struct {
+	// Schema is the name of the schema for the record. It is always `RELEASE_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event: "release", "create" or "delete".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event, for example "published". For `create` and `delete` events this
+	// is "created" and "deleted" respectively.
+	Action string `json:"action"`
+	// Repo is the name of the repository where the release or tag was created.
+	Repo string `json:"repo"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository where the release or tag was created.
+	FullName string `json:"full_name"`
+	// Tag is the name of the tag.
+	Tag string `json:"tag"`
+	// Target is the commitish (branch or commit hash) the release was created from. It is empty for `create` and `delete` events.
+	Target string `json:"target,omitempty"`
+	// Name is the name of the release.
+	Name string `json:"name,omitempty"`
+	// Draft is a boolean flag indicating whether the release is a draft.
+	Draft bool `json:"draft"`
+	// Prerelease is a boolean flag indicating whether the release is a pre-release.
+	Prerelease bool `json:"prerelease"`
+	// Author is the login of the user who created the release or tag.
+	Author string `json:"author"`
+	// HTMLURL is the URL of the (HTML) web page for the release.
+	HTMLURL string `json:"html_url,omitempty"`
+	// Assets is the list of files attached to the release.
+	Assets []*ReleaseAsset `json:"assets"`
+	// ref_type is the type of ref ("tag" or "branch") created or deleted by `create` and `delete` events. It is empty for `release` events.
+	ref_type string
+}
+
+// newReleaseRecordFromRelease returns a new `ReleaseRecord` instance derived from 'event'.
+func newReleaseRecordFromRelease(event *gogithub.ReleaseEvent) *ReleaseRecord {
+
+	release := event.GetRelease()
+	repo := event.GetRepo()
+
+	rec := &ReleaseRecord{
+		Schema:     RELEASE_SCHEMA,
+		Version:    RELEASE_SCHEMA_VERSION,
+		Event:      "release",
+		Action:     event.GetAction(),
+		Repo:       repo.GetName(),
+		FullName:   repo.GetFullName(),
+		Tag:        release.GetTagName(),
+		Target:     release.GetTargetCommitish(),
+		Name:       release.GetName(),
+		Draft:      release.GetDraft(),
+		Prerelease: release.GetPrerelease(),
+		Author:     release.GetAuthor().GetLogin(),
+		HTMLURL:    release.GetHTMLURL(),
+		Assets:     make([]*ReleaseAsset, len(release.Assets)),
+	}
+
+	for idx, a := range release.Assets {
+
+		rec.Assets[idx] = &ReleaseAsset{
+			Name:        a.GetName(),
+			ContentType: a.GetContentType(),
+			Size:        a.GetSize(),
+			DownloadURL: a.GetBrowserDownloadURL(),
+		}
+	}
+
+	return rec
+}
+
+// newReleaseRecordFromTag returns a new `ReleaseRecord` instance for the tag 'tag' which was created or deleted
+// (according to 'event_type') in 'repo' by 'sender'.
+func newReleaseRecordFromTag(event_type string, tag string, repo *gogithub.Repository, sender *gogithub.User) *ReleaseRecord {
+
+	action := "created"
+
+	if event_type == "delete" {
+		action = "deleted"
+	}
+
+	rec := &ReleaseRecord{
+		Schema:   RELEASE_SCHEMA,
+		Version:  RELEASE_SCHEMA_VERSION,
+		Event:    event_type,
+		Action:   action,
+		Repo:     repo.GetName(),
+		FullName: repo.GetFullName(),
+		Tag:      tag,
+		Author:   sender.GetLogin(),
+		Assets:   make([]*ReleaseAsset, 0),
+	}
+
+	return rec
+}
+
+// newReleaseRecordFromCreate returns a new `ReleaseRecord` instance derived from the `create` event 'event'.
+func newReleaseRecordFromCreate(event *gogithub.CreateEvent) *ReleaseRecord {
+
+	rec := newReleaseRecordFromTag("create", event.GetRef(), event.GetRepo(), event.GetSender())
+	rec.ref_type = event.GetRefType()
+
+	return rec
+}
+
+// newReleaseRecordFromDelete returns a new `ReleaseRecord` instance derived from the `delete` event 'event'.
+func newReleaseRecordFromDelete(event *gogithub.DeleteEvent) *ReleaseRecord {
+
+	rec := newReleaseRecordFromTag("delete", event.GetRef(), event.GetRepo(), event.GetSender())
+	rec.ref_type = event.GetRefType()
+
+	return rec
+}
+
+// releaseRuleFieldNames is the list of field names that rules for release and tag events may be evaluated against.
+var releaseRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"tag",
+	"target",
+	"name",
+	"author",
+	"draft",
+	"prerelease",
+	"asset",
+}
+
+// releaseRuleFields returns the `RuleFields` for 'rec' used to evaluate rules.
+func releaseRuleFields(rec *ReleaseRecord) RuleFields {
+
+	assets := make([]string, len(rec.Assets))
+
+	for idx, a := range rec.Assets {
+		assets[idx] = a.Name
+	}
+
+	fields := RuleFields{
+		"event":      []string{rec.Event},
+		"action":     []string{rec.Action},
+		"repo":       []string{rec.Repo},
+		"full_name":  []string{rec.FullName},
+		"tag":        []string{rec.Tag},
+		"target":     []string{rec.Target},
+		"name":       []string{rec.Name},
+		"author":     []string{rec.Author},
+		"draft":      []string{strconv.FormatBool(rec.Draft)},
+		"prerelease": []string{strconv.FormatBool(rec.Prerelease)},
+		"asset":      assets,
+	}
+
+	return fields
+}
+
+// releaseRows returns the CSV rows for 'rec', one for each asset (or a single row if there are no assets): the name of the
+// repository, the tag, the action, the target, the asset name and the asset download URL.
+func releaseRows(rec *ReleaseRecord) [][]string {
+
+	rows := make([][]string, 0)
+
+	if len(rec.Assets) == 0 {
+		row := []string{rec.Repo, rec.Tag, rec.Action, rec.Target, "", ""}
+		rows = append(rows, row)
+	}
+
+	for _, a := range rec.Assets {
+		row := []string{rec.Repo, rec.Tag, rec.Action, rec.Target, a.Name, a.DownloadURL}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
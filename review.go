@@ -0,0 +1,205 @@
+package github
+
+import (
+	"strconv"
+	"strings"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// REVIEW_SCHEMA is the name of the schema used to encode `pull_request_review`, `pull_request_review_comment` and
+// `pull_request_review_thread` events as JSON.
+const REVIEW_SCHEMA string = "pull_request_review"
+
+// REVIEW_SCHEMA_VERSION is the current version of the `REVIEW_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const REVIEW_SCHEMA_VERSION int = 1
+
+// ReviewRecord is the JSON-encoded representation of a GitHub `pull_request_review`, `pull_request_review_comment` or
+// `pull_request_review_thread` event produced by transformations in this package.
+type ReviewRecord struct {
+	// Schema is the name of the schema for the record. It is always `REVIEW_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event: "pull_request_review", "pull_request_review_comment" or "pull_request_review_thread".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event, for example "submitted", "created" or "resolved".
+	Action string `json:"action"`
+	// Repo is the name of the repository the pull request was opened against.
+	Repo string `json:"repo"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository the pull request was opened against.
+	FullName string `json:"full_name"`
+	// Number is the pull request number.
+	Number int `json:"number"`
+	// Title is the title of the pull request.
+	Title string `json:"title"`
+	// Author is the login of the user who opened the pull request.
+	Author string `json:"author"`
+	// Base is the name of the branch the pull request will be merged in to.
+	Base string `json:"base"`
+	// Reviewer is the login of the user who wrote the review or comment. For `pull_request_review_thread` events it is
+	// the user who wrote the first comment in the thread.
+	Reviewer string `json:"reviewer"`
+	// Association is the relationship of 'Reviewer' to the repository, for example "OWNER", "MEMBER" or "CONTRIBUTOR".
+	Association string `json:"association"`
+	// State is the (lower-case) state of the review, for example "approved", "changes_requested" or "commented". It is
+	// empty for `pull_request_review_comment` and `pull_request_review_thread` events.
+	State string `json:"state,omitempty"`
+	// ReviewID is the unique ID of the review the comment (or thread) belongs to.
+	ReviewID int64 `json:"review_id,omitempty"`
+	// CommentID is the unique ID of the comment, or the first comment in the thread. It is zero for `pull_request_review` events.
+	CommentID int64 `json:"comment_id,omitempty"`
+	// Path is the path of the file that was commented on. It is empty for `pull_request_review` events.
+	Path string `json:"path,omitempty"`
+	// Line is the line of the file that was commented on, or the original line if the comment is outdated. It is zero for
+	// `pull_request_review` events.
+	Line int `json:"line,omitempty"`
+	// StartLine is the first line of the range of lines that was commented on, for multi-line comments.
+	StartLine int `json:"start_line,omitempty"`
+	// SHA is the hash of the commit that was reviewed or commented on.
+	SHA string `json:"sha"`
+	// Body is the body of the review or comment.
+	Body string `json:"body,omitempty"`
+	// Comments is the number of comments in the thread. It is zero for `pull_request_review` and `pull_request_review_comment` events.
+	Comments int `json:"comments,omitempty"`
+	// HTMLURL is the URL of the (HTML) web page for the review or comment.
+	HTMLURL string `json:"html_url"`
+	// Actor is the login of the user who triggered the event.
+	Actor string `json:"actor"`
+}
+
+// newReviewRecord returns a new `ReviewRecord` instance for the event 'event_type' derived from 'action', 'pr', 'repo' and 'sender'.
+func newReviewRecord(event_type string, action string, pr *gogithub.PullRequest, repo *gogithub.Repository, sender *gogithub.User) *ReviewRecord {
+
+	rec := &ReviewRecord{
+		Schema:   REVIEW_SCHEMA,
+		Version:  REVIEW_SCHEMA_VERSION,
+		Event:    event_type,
+		Action:   action,
+		Repo:     repo.GetName(),
+		FullName: repo.GetFullName(),
+		Number:   pr.GetNumber(),
+		Title:    pr.GetTitle(),
+		Author:   pr.GetUser().GetLogin(),
+		Base:     pr.GetBase().GetRef(),
+		Actor:    sender.GetLogin(),
+	}
+
+	return rec
+}
+
+// setReviewComment assigns the reviewer, location and URL properties of 'rec' from 'comment'.
+func setReviewComment(rec *ReviewRecord, comment *gogithub.PullRequestComment) {
+
+	rec.Reviewer = comment.GetUser().GetLogin()
+	rec.Association = comment.GetAuthorAssociation()
+	rec.ReviewID = comment.GetPullRequestReviewID()
+	rec.CommentID = comment.GetID()
+	rec.Path = comment.GetPath()
+	rec.Line = comment.GetLine()
+	rec.StartLine = comment.GetStartLine()
+	rec.SHA = comment.GetCommitID()
+	rec.Body = comment.GetBody()
+	rec.HTMLURL = comment.GetHTMLURL()
+
+	// Comments on lines which have since changed ("outdated" comments) have no line
+
+	if rec.Line == 0 {
+		rec.Line = comment.GetOriginalLine()
+		rec.StartLine = comment.GetOriginalStartLine()
+	}
+}
+
+// newReviewRecordFromReview returns a new `ReviewRecord` instance derived from 'event'.
+func newReviewRecordFromReview(event *gogithub.PullRequestReviewEvent) *ReviewRecord {
+
+	review := event.GetReview()
+
+	rec := newReviewRecord("pull_request_review", event.GetAction(), event.GetPullRequest(), event.GetRepo(), event.GetSender())
+	rec.Reviewer = review.GetUser().GetLogin()
+	rec.Association = review.GetAuthorAssociation()
+	rec.State = strings.ToLower(review.GetState())
+	rec.ReviewID = review.GetID()
+	rec.SHA = review.GetCommitID()
+	rec.Body = review.GetBody()
+	rec.HTMLURL = review.GetHTMLURL()
+
+	return rec
+}
+
+// newReviewRecordFromComment returns a new `ReviewRecord` instance derived from 'event'.
+func newReviewRecordFromComment(event *gogithub.PullRequestReviewCommentEvent) *ReviewRecord {
+
+	rec := newReviewRecord("pull_request_review_comment", event.GetAction(), event.GetPullRequest(), event.GetRepo(), event.GetSender())
+	setReviewComment(rec, event.GetComment())
+
+	return rec
+}
+
+// newReviewRecordFromThread returns a new `ReviewRecord` instance derived from 'event'. The reviewer, location and URL
+// are those of the first comment in the thread.
+func newReviewRecordFromThread(event *gogithub.PullRequestReviewThreadEvent) *ReviewRecord {
+
+	thread := event.GetThread()
+
+	rec := newReviewRecord("pull_request_review_thread", event.GetAction(), event.GetPullRequest(), event.GetRepo(), event.GetSender())
+	rec.Comments = len(thread.Comments)
+
+	if len(thread.Comments) > 0 {
+		setReviewComment(rec, thread.Comments[0])
+	}
+
+	return rec
+}
+
+// reviewRuleFieldNames is the list of field names that rules for review events may be evaluated against.
+var reviewRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"number",
+	"author",
+	"base",
+	"reviewer",
+	"association",
+	"state",
+	"path",
+	"actor",
+}
+
+// reviewRuleFields returns the `RuleFields` for 'rec' used to evaluate rules.
+func reviewRuleFields(rec *ReviewRecord) RuleFields {
+
+	fields := RuleFields{
+		"event":       []string{rec.Event},
+		"action":      []string{rec.Action},
+		"repo":        []string{rec.Repo},
+		"full_name":   []string{rec.FullName},
+		"number":      []string{strconv.Itoa(rec.Number)},
+		"author":      []string{rec.Author},
+		"base":        []string{rec.Base},
+		"reviewer":    []string{rec.Reviewer},
+		"association": []string{rec.Association},
+		"state":       []string{rec.State},
+		"path":        []string{rec.Path},
+		"actor":       []string{rec.Actor},
+	}
+
+	return fields
+}
+
+// reviewRows returns the CSV rows for 'rec': the name of the repository, the pull request number, the name of the event, the
+// reviewer, the review state, the file path, the line number and the URL.
+func reviewRows(rec *ReviewRecord) [][]string {
+
+	line := ""
+
+	if rec.Line > 0 {
+		line = strconv.Itoa(rec.Line)
+	}
+
+	row := []string{rec.Repo, strconv.Itoa(rec.Number), rec.Event, rec.Reviewer, rec.State, rec.Path, line, rec.HTMLURL}
+	return [][]string{row}
+}
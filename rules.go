@@ -0,0 +1,363 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+)
+
+// OPERATOR_EQUALS matches if any value for a field is equal to the rule's value.
+const OPERATOR_EQUALS string = "=="
+
+// OPERATOR_NOT_EQUALS matches if no value for a field is equal to the rule's value.
+const OPERATOR_NOT_EQUALS string = "!="
+
+// OPERATOR_MATCHES matches if any value for a field matches the rule's value, parsed as a regular expression.
+const OPERATOR_MATCHES string = "=~"
+
+// OPERATOR_NOT_MATCHES matches if no value for a field matches the rule's value, parsed as a regular expression.
+const OPERATOR_NOT_MATCHES string = "!~"
+
+// OPERATOR_GLOB matches if any value for a field matches the rule's value, parsed as a `path.Match` pattern. As with `path.Match`
+// a "*" does not match "/" but a "**" path segment will match zero or more path segments, for example "data/**/*.geojson".
+const OPERATOR_GLOB string = "*="
+
+// OPERATOR_GREATER_THAN matches if any value for a field is a number greater than the rule's value.
+const OPERATOR_GREATER_THAN string = ">"
+
+// OPERATOR_GREATER_THAN_EQUALS matches if any value for a field is a number greater than or equal to the rule's value.
+const OPERATOR_GREATER_THAN_EQUALS string = ">="
+
+// OPERATOR_LESS_THAN matches if any value for a field is a number less than the rule's value.
+const OPERATOR_LESS_THAN string = "<"
+
+// OPERATOR_LESS_THAN_EQUALS matches if any value for a field is a number less than or equal to the rule's value.
+const OPERATOR_LESS_THAN_EQUALS string = "<="
+
+// operators is the list of valid rule operators, ordered so that longer operators are tested before their prefixes.
+var operators = []string{
+	OPERATOR_EQUALS,
+	OPERATOR_NOT_EQUALS,
+	OPERATOR_MATCHES,
+	OPERATOR_NOT_MATCHES,
+	OPERATOR_GLOB,
+	OPERATOR_GREATER_THAN_EQUALS,
+	OPERATOR_LESS_THAN_EQUALS,
+	OPERATOR_GREATER_THAN,
+	OPERATOR_LESS_THAN,
+}
+
+var re_field = regexp.MustCompile(`^[A-Za-z0-9_\-\.]+$`)
+
+// RuleFields is a map of field names and their (zero or more) values that rules are evaluated against.
+type RuleFields map[string][]string
+
+// Rule is a single condition, expressed as '{FIELD}{OPERATOR}{VALUE}', that is evaluated against `RuleFields`.
+type Rule struct {
+	// Field is the name of the field the rule is evaluated against.
+	Field string
+	// Operator is the comparison operator for the rule.
+	Operator string
+	// Value is the value that field values are compared to.
+	Value string
+	// re is the compiled regular expression for rules using `OPERATOR_MATCHES` or `OPERATOR_NOT_MATCHES`.
+	re *regexp.Regexp
+	// number is the numeric value for rules using numeric comparison operators.
+	number int
+}
+
+// ParseRule parses 'expr', a string in the form of '{FIELD}{OPERATOR}{VALUE}', in to a new `Rule` instance. For example:
+// `ref==refs/heads/main`, `message=~\[skip webhookd\]` or `path*=data/*.geojson`. Field names are case-insensitive and
+// are always lower-cased.
+func ParseRule(expr string) (*Rule, error) {
+
+	idx := -1
+	op := ""
+
+	for _, candidate := range operators {
+
+		i := strings.Index(expr, candidate)
+
+		if i == -1 {
+			continue
+		}
+
+		if idx == -1 || i < idx {
+			idx = i
+			op = candidate
+		}
+	}
+
+	if idx == -1 {
+		return nil, fmt.Errorf("Rule '%s' is missing a valid operator", expr)
+	}
+
+	field := strings.ToLower(strings.TrimSpace(expr[0:idx]))
+	value := strings.TrimSpace(expr[idx+len(op):])
+
+	if !re_field.MatchString(field) {
+		return nil, fmt.Errorf("Rule '%s' has an invalid field name", expr)
+	}
+
+	r := &Rule{
+		Field:    field,
+		Operator: op,
+		Value:    value,
+	}
+
+	switch op {
+	case OPERATOR_MATCHES, OPERATOR_NOT_MATCHES:
+
+		re, err := regexp.Compile(value)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to compile regular expression for rule '%s', %w", expr, err)
+		}
+
+		r.re = re
+
+	case OPERATOR_GLOB:
+
+		_, err := path.Match(value, "")
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern for rule '%s', %w", expr, err)
+		}
+
+	case OPERATOR_GREATER_THAN, OPERATOR_GREATER_THAN_EQUALS, OPERATOR_LESS_THAN, OPERATOR_LESS_THAN_EQUALS:
+
+		n, err := strconv.Atoi(value)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid number for rule '%s', %w", expr, err)
+		}
+
+		r.number = n
+	}
+
+	return r, nil
+}
+
+// String returns the string representation of 'r'.
+func (r *Rule) String() string {
+	return fmt.Sprintf("%s%s%s", r.Field, r.Operator, r.Value)
+}
+
+// Matches returns a boolean value indicating whether 'fields' satisfies the condition defined by 'r'. Fields that are
+// absent from 'fields' are treated as having no values.
+func (r *Rule) Matches(fields RuleFields) bool {
+
+	values := fields[r.Field]
+
+	switch r.Operator {
+	case OPERATOR_NOT_EQUALS:
+
+		for _, v := range values {
+			if v == r.Value {
+				return false
+			}
+		}
+
+		return true
+
+	case OPERATOR_NOT_MATCHES:
+
+		for _, v := range values {
+			if r.re.MatchString(v) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, v := range values {
+
+		if r.matchValue(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchValue returns a boolean value indicating whether 'v' satisfies a (positive) rule.
+func (r *Rule) matchValue(v string) bool {
+
+	switch r.Operator {
+	case OPERATOR_EQUALS:
+		return v == r.Value
+	case OPERATOR_MATCHES:
+		return r.re.MatchString(v)
+	case OPERATOR_GLOB:
+		return matchGlob(r.Value, v)
+	}
+
+	n, err := strconv.Atoi(v)
+
+	if err != nil {
+		return false
+	}
+
+	switch r.Operator {
+	case OPERATOR_GREATER_THAN:
+		return n > r.number
+	case OPERATOR_GREATER_THAN_EQUALS:
+		return n >= r.number
+	case OPERATOR_LESS_THAN:
+		return n < r.number
+	case OPERATOR_LESS_THAN_EQUALS:
+		return n <= r.number
+	default:
+		return false
+	}
+}
+
+// RuleSet is a collection of rules used to determine whether a transformation should halt processing a message.
+type RuleSet struct {
+	// Halt is the list of rules which, if any one of them matches, will cause processing to halt.
+	Halt []*Rule
+	// Only is the list of rules which must all match for processing to continue.
+	Only []*Rule
+}
+
+// NewRuleSetFromQuery returns a new `RuleSet` instance derived from the (zero or more) `?halt_if=` and `?only_if=` parameters in 'q'.
+func NewRuleSetFromQuery(q url.Values) (*RuleSet, error) {
+
+	rs := &RuleSet{
+		Halt: make([]*Rule, 0),
+		Only: make([]*Rule, 0),
+	}
+
+	for _, expr := range q["halt_if"] {
+
+		r, err := ParseRule(expr)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?halt_if= parameter, %w", err)
+		}
+
+		rs.Halt = append(rs.Halt, r)
+	}
+
+	for _, expr := range q["only_if"] {
+
+		r, err := ParseRule(expr)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?only_if= parameter, %w", err)
+		}
+
+		rs.Only = append(rs.Only, r)
+	}
+
+	return rs, nil
+}
+
+// Validate ensures that every rule in 'rs' is evaluated against a field in 'known'. Entries in 'known' ending in ".*" will
+// match any field name sharing the same prefix (for example "trailer.*").
+func (rs *RuleSet) Validate(known []string) error {
+
+	is_known := func(field string) bool {
+
+		for _, k := range known {
+
+			if k == field {
+				return true
+			}
+
+			if strings.HasSuffix(k, ".*") && strings.HasPrefix(field, strings.TrimSuffix(k, "*")) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, rules := range [][]*Rule{rs.Halt, rs.Only} {
+
+		for _, r := range rules {
+
+			if !is_known(r.Field) {
+				return fmt.Errorf("Unknown field '%s' in rule '%s'", r.Field, r.String())
+			}
+		}
+	}
+
+	return nil
+}
+
+// Partition returns two new `RuleSet` instances: The first containing the rules in 'rs' that are evaluated against any of
+// 'fields' and the second containing all the other rules in 'rs'.
+func (rs *RuleSet) Partition(fields []string) (*RuleSet, *RuleSet) {
+
+	matched := &RuleSet{
+		Halt: make([]*Rule, 0),
+		Only: make([]*Rule, 0),
+	}
+
+	other := &RuleSet{
+		Halt: make([]*Rule, 0),
+		Only: make([]*Rule, 0),
+	}
+
+	has_field := func(r *Rule) bool {
+
+		for _, f := range fields {
+
+			if f == r.Field {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, r := range rs.Halt {
+
+		if has_field(r) {
+			matched.Halt = append(matched.Halt, r)
+		} else {
+			other.Halt = append(other.Halt, r)
+		}
+	}
+
+	for _, r := range rs.Only {
+
+		if has_field(r) {
+			matched.Only = append(matched.Only, r)
+		} else {
+			other.Only = append(other.Only, r)
+		}
+	}
+
+	return matched, other
+}
+
+// Evaluate tests 'fields' against the rules in 'rs' returning a `webhookd.WebhookError` with code `webhookd.HaltEvent`
+// if any `Halt` rule matches or any `Only` rule does not match. Otherwise it returns nil.
+func (rs *RuleSet) Evaluate(fields RuleFields) *webhookd.WebhookError {
+
+	for _, r := range rs.Halt {
+
+		if r.Matches(fields) {
+			msg := fmt.Sprintf("Halt (%s)", r.String())
+			return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+		}
+	}
+
+	for _, r := range rs.Only {
+
+		if !r.Matches(fields) {
+			msg := fmt.Sprintf("Halt (not %s)", r.String())
+			return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+		}
+	}
+
+	return nil
+}
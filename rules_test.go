@@ -0,0 +1,101 @@
+package github
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+)
+
+func TestParseRule(t *testing.T) {
+
+	tests := map[string][]string{
+		"ref==refs/heads/main":          {"ref", OPERATOR_EQUALS, "refs/heads/main"},
+		"sender != sfomuseumbot":        {"sender", OPERATOR_NOT_EQUALS, "sfomuseumbot"},
+		`message=~\[skip webhookd\]`:    {"message", OPERATOR_MATCHES, `\[skip webhookd\]`},
+		"path*=data/*/*.geojson":        {"path", OPERATOR_GLOB, "data/*/*.geojson"},
+		"changes>=100":                  {"changes", OPERATOR_GREATER_THAN_EQUALS, "100"},
+		"trailer.WOF-Source<5":          {"trailer.wof-source", OPERATOR_LESS_THAN, "5"},
+		"committer_email!~@example.com": {"committer_email", OPERATOR_NOT_MATCHES, "@example.com"},
+	}
+
+	for expr, expected := range tests {
+
+		r, err := ParseRule(expr)
+
+		if err != nil {
+			t.Fatalf("Failed to parse rule '%s', %v", expr, err)
+		}
+
+		if r.Field != expected[0] || r.Operator != expected[1] || r.Value != expected[2] {
+			t.Fatalf("Unexpected parsing for rule '%s': %s %s %s", expr, r.Field, r.Operator, r.Value)
+		}
+	}
+
+	invalid := []string{
+		"ref",
+		"==refs/heads/main",
+		"message=~[",
+		"changes>lots",
+	}
+
+	for _, expr := range invalid {
+
+		_, err := ParseRule(expr)
+
+		if err == nil {
+			t.Fatalf("Expected rule '%s' to fail parsing", expr)
+		}
+	}
+}
+
+func TestRuleSet(t *testing.T) {
+
+	fields := RuleFields{
+		"ref":     {"refs/heads/main"},
+		"message": {"first commit", "second commit [skip webhookd]"},
+		"path":    {"data/101/736/545/101736545.geojson", "README.md"},
+		"changes": {"2"},
+	}
+
+	tests := map[string]bool{
+		"halt_if=ref%3D%3Drefs/heads/main":                true,
+		"halt_if=ref%3D%3Drefs/heads/dev":                 false,
+		"halt_if=message%3D~%5C%5Bskip+webhookd%5C%5D":    true,
+		"only_if=path*%3Ddata/*/*/*/*.geojson":            false,
+		"only_if=path*%3Ddocs/*":                          true,
+		"only_if=path*%3Ddata/*":                          true,
+		"only_if=path*%3Ddata/**":                         false,
+		"only_if=path*%3Ddata/**/*.geojson":               false,
+		"only_if=path*%3D**/101736545.geojson":            false,
+		"only_if=path*%3Ddata/**/*.csv":                   true,
+		"only_if=changes>1&only_if=ref!%3Drefs/heads/dev": false,
+		"halt_if=changes>10":                              false,
+		"only_if=sender%3D%3Dsfomuseumbot":                true,
+	}
+
+	for raw, expected_halt := range tests {
+
+		q, err := url.ParseQuery(raw)
+
+		if err != nil {
+			t.Fatalf("Failed to parse query '%s', %v", raw, err)
+		}
+
+		rs, err := NewRuleSetFromQuery(q)
+
+		if err != nil {
+			t.Fatalf("Failed to create rule set for '%s', %v", raw, err)
+		}
+
+		halt_err := rs.Evaluate(fields)
+
+		if expected_halt && (halt_err == nil || halt_err.Code != webhookd.HaltEvent) {
+			t.Fatalf("Expected '%s' to halt", raw)
+		}
+
+		if !expected_halt && halt_err != nil {
+			t.Fatalf("Expected '%s' not to halt, %v", raw, halt_err)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package github
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// SECURITY_SCHEMA is the name of the schema used to encode `code_scanning_alert`, `secret_scanning_alert`,
+// `repository_vulnerability_alert` and `security_advisory` events as JSON.
+const SECURITY_SCHEMA string = "security_alert"
+
+// SECURITY_SCHEMA_VERSION is the current version of the `SECURITY_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const SECURITY_SCHEMA_VERSION int = 1
+
+// SEVERITY_LOW is the normalized severity for low severity alerts and code scanning "note" alerts.
+const SEVERITY_LOW string = "low"
+
+// SEVERITY_MEDIUM is the normalized severity for medium (or "moderate") severity alerts and code scanning "warning" alerts.
+const SEVERITY_MEDIUM string = "medium"
+
+// SEVERITY_HIGH is the normalized severity for high severity alerts, code scanning "error" alerts and all secret scanning alerts.
+const SEVERITY_HIGH string = "high"
+
+// SEVERITY_CRITICAL is the normalized severity for critical severity alerts.
+const SEVERITY_CRITICAL string = "critical"
+
+// REDACTED is the value assigned to secret values and locations which have been redacted.
+const REDACTED string = "[redacted]"
+
+// severityRanks maps normalized severities to their relative rank.
+var severityRanks = map[string]int{
+	SEVERITY_LOW:      1,
+	SEVERITY_MEDIUM:   2,
+	SEVERITY_HIGH:     3,
+	SEVERITY_CRITICAL: 4,
+}
+
+// SecurityRecord is the JSON-encoded representation of a GitHub `code_scanning_alert`, `secret_scanning_alert`,
+// `repository_vulnerability_alert` or `security_advisory` event produced by transformations in this package.
+type SecurityRecord struct {
+	// Schema is the name of the schema for the record. It is always `SECURITY_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event, for example "code_scanning_alert".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event, for example "created", "dismiss" or "published".
+	Action string `json:"action"`
+	// Repo is the name of the repository the alert belongs to. It is empty for `security_advisory` events.
+	Repo string `json:"repo,omitempty"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository the alert belongs to. It is empty for `security_advisory` events.
+	FullName string `json:"full_name,omitempty"`
+	// ID is the identifier for the alert: the alert number for code and secret scanning alerts or the GitHub Security Advisory
+	// (GHSA) ID for vulnerability alerts and advisories.
+	ID string `json:"id"`
+	// Severity is the normalized severity of the alert: `SEVERITY_LOW`, `SEVERITY_MEDIUM`, `SEVERITY_HIGH` or `SEVERITY_CRITICAL`.
+	Severity string `json:"severity"`
+	// State is the state of the alert, for example "open", "dismissed", "fixed" or "resolved". For advisories it is "published" or "withdrawn".
+	State string `json:"state"`
+	// Title is a short description of the alert.
+	Title string `json:"title"`
+	// Rule is the code scanning rule, secret type or affected package that triggered the alert.
+	Rule string `json:"rule,omitempty"`
+	// Tool is the name of the code scanning tool which created the alert.
+	Tool string `json:"tool,omitempty"`
+	// Ref is the ref the alert was found in.
+	Ref string `json:"ref,omitempty"`
+	// Location is the location of the alert: the path and line number for code scanning alerts or the locations URL for secret scanning alerts.
+	Location string `json:"location,omitempty"`
+	// Secret is the secret value for secret scanning alerts.
+	Secret string `json:"secret,omitempty"`
+	// HTMLURL is the URL of the (HTML) web page for the alert or a reference URL for vulnerability alerts and advisories.
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+// normalizeSeverity returns the normalized severity for 'severity' which may be a security severity level, a GitHub
+// advisory severity or a code scanning rule severity.
+func normalizeSeverity(severity string) string {
+
+	switch strings.ToLower(severity) {
+	case "critical":
+		return SEVERITY_CRITICAL
+	case "high", "error":
+		return SEVERITY_HIGH
+	case "medium", "moderate", "warning":
+		return SEVERITY_MEDIUM
+	case "low", "note":
+		return SEVERITY_LOW
+	default:
+		return ""
+	}
+}
+
+// parseSeverity returns the normalized severity for 'severity' or an error if it is not a valid severity.
+func parseSeverity(severity string) (string, error) {
+
+	v := normalizeSeverity(severity)
+
+	if v == "" {
+		return "", fmt.Errorf("Invalid severity '%s'", severity)
+	}
+
+	return v, nil
+}
+
+// meetsSeverity returns a boolean value indicating whether 'severity' is greater than or equal to 'min_severity'. If
+// 'min_severity' is empty every severity matches.
+func meetsSeverity(severity string, min_severity string) bool {
+
+	if min_severity == "" {
+		return true
+	}
+
+	return severityRanks[severity] >= severityRanks[min_severity]
+}
+
+// newSecurityRecordFromCodeScanning returns a new `SecurityRecord` instance derived from 'event'.
+func newSecurityRecordFromCodeScanning(event *gogithub.CodeScanningAlertEvent) *SecurityRecord {
+
+	alert := event.GetAlert()
+	rule := alert.GetRule()
+	repo := event.GetRepo()
+
+	severity := rule.GetSecuritySeverityLevel()
+
+	if severity == "" {
+		severity = rule.GetSeverity()
+	}
+
+	rec := &SecurityRecord{
+		Schema:   SECURITY_SCHEMA,
+		Version:  SECURITY_SCHEMA_VERSION,
+		Event:    "code_scanning_alert",
+		Action:   event.GetAction(),
+		Repo:     repo.GetName(),
+		FullName: repo.GetFullName(),
+		ID:       strconv.Itoa(alert.GetNumber()),
+		Severity: normalizeSeverity(severity),
+		State:    alert.GetState(),
+		Title:    rule.GetDescription(),
+		Rule:     rule.GetID(),
+		Tool:     alert.GetTool().GetName(),
+		Ref:      event.GetRef(),
+		HTMLURL:  alert.GetHTMLURL(),
+	}
+
+	loc := alert.GetMostRecentInstance().GetLocation()
+
+	if loc.GetPath() != "" {
+		rec.Location = fmt.Sprintf("%s:%d", loc.GetPath(), loc.GetStartLine())
+	}
+
+	return rec
+}
+
+// newSecurityRecordFromSecretScanning returns a new `SecurityRecord` instance derived from 'event'. Secret scanning alerts
+// do not have a severity and are always assigned `SEVERITY_HIGH`.
+func newSecurityRecordFromSecretScanning(event *gogithub.SecretScanningAlertEvent) *SecurityRecord {
+
+	alert := event.GetAlert()
+	repo := event.GetRepo()
+
+	rec := &SecurityRecord{
+		Schema:   SECURITY_SCHEMA,
+		Version:  SECURITY_SCHEMA_VERSION,
+		Event:    "secret_scanning_alert",
+		Action:   event.GetAction(),
+		Repo:     repo.GetName(),
+		FullName: repo.GetFullName(),
+		ID:       strconv.Itoa(alert.GetNumber()),
+		Severity: SEVERITY_HIGH,
+		State:    alert.GetState(),
+		Title:    fmt.Sprintf("Secret detected (%s)", alert.GetSecretType()),
+		Rule:     alert.GetSecretType(),
+		Location: alert.GetLocationsURL(),
+		Secret:   alert.GetSecret(),
+		HTMLURL:  alert.GetHTMLURL(),
+	}
+
+	return rec
+}
+
+// newSecurityRecordFromVulnerability returns a new `SecurityRecord` instance derived from 'event'. The state of the alert is
+// derived from its action.
+func newSecurityRecordFromVulnerability(event *gogithub.RepositoryVulnerabilityAlertEvent) *SecurityRecord {
+
+	alert := event.GetAlert()
+	repo := event.GetRepository()
+
+	state := "open"
+
+	switch event.GetAction() {
+	case "dismiss":
+		state = "dismissed"
+	case "resolve":
+		state = "fixed"
+	}
+
+	rec := &SecurityRecord{
+		Schema:   SECURITY_SCHEMA,
+		Version:  SECURITY_SCHEMA_VERSION,
+		Event:    "repository_vulnerability_alert",
+		Action:   event.GetAction(),
+		Repo:     repo.GetName(),
+		FullName: repo.GetFullName(),
+		ID:       alert.GetGitHubSecurityAdvisoryID(),
+		Severity: normalizeSeverity(alert.GetSeverity()),
+		State:    state,
+		Title:    fmt.Sprintf("%s %s (%s)", alert.GetAffectedPackageName(), alert.GetAffectedRange(), alert.GetExternalIdentifier()),
+		Rule:     alert.GetAffectedPackageName(),
+		HTMLURL:  alert.GetExternalReference(),
+	}
+
+	return rec
+}
+
+// newSecurityRecordFromAdvisory returns a new `SecurityRecord` instance derived from 'event'. The rule for advisories is
+// the name of the first vulnerable package.
+func newSecurityRecordFromAdvisory(event *gogithub.SecurityAdvisoryEvent) *SecurityRecord {
+
+	advisory := event.GetSecurityAdvisory()
+
+	state := "published"
+
+	if advisory.WithdrawnAt != nil {
+		state = "withdrawn"
+	}
+
+	rec := &SecurityRecord{
+		Schema:   SECURITY_SCHEMA,
+		Version:  SECURITY_SCHEMA_VERSION,
+		Event:    "security_advisory",
+		Action:   event.GetAction(),
+		ID:       advisory.GetGHSAID(),
+		Severity: normalizeSeverity(advisory.GetSeverity()),
+		State:    state,
+		Title:    advisory.GetSummary(),
+	}
+
+	if len(advisory.Vulnerabilities) > 0 {
+		rec.Rule = advisory.Vulnerabilities[0].GetPackage().GetName()
+	}
+
+	if len(advisory.References) > 0 {
+		rec.HTMLURL = advisory.References[0].GetURL()
+	}
+
+	return rec
+}
+
+// redactSecurityRecord replaces the secret value and location of secret scanning alerts in 'rec' with `REDACTED`.
+func redactSecurityRecord(rec *SecurityRecord) {
+
+	if rec.Event != "secret_scanning_alert" {
+		return
+	}
+
+	if rec.Secret != "" {
+		rec.Secret = REDACTED
+	}
+
+	if rec.Location != "" {
+		rec.Location = REDACTED
+	}
+}
+
+// securityRuleFieldNames is the list of field names that rules for security events may be evaluated against.
+var securityRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"id",
+	"severity",
+	"state",
+	"title",
+	"rule",
+	"tool",
+	"ref",
+}
+
+// securityRuleFields returns the `RuleFields` for 'rec' used to evaluate rules.
+func securityRuleFields(rec *SecurityRecord) RuleFields {
+
+	fields := RuleFields{
+		"event":     []string{rec.Event},
+		"action":    []string{rec.Action},
+		"repo":      []string{rec.Repo},
+		"full_name": []string{rec.FullName},
+		"id":        []string{rec.ID},
+		"severity":  []string{rec.Severity},
+		"state":     []string{rec.State},
+		"title":     []string{rec.Title},
+		"rule":      []string{rec.Rule},
+		"tool":      []string{rec.Tool},
+		"ref":       []string{rec.Ref},
+	}
+
+	return fields
+}
+
+// securityRows returns the CSV rows for 'rec': the name of the event, the name of the repository, the alert ID, the normalized
+// severity, the state, the title, the location and the URL.
+func securityRows(rec *SecurityRecord) [][]string {
+
+	row := []string{rec.Event, rec.Repo, rec.ID, rec.Severity, rec.State, rec.Title, rec.Location, rec.HTMLURL}
+	return [][]string{row}
+}
@@ -0,0 +1,108 @@
+package github
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// TRAILER_CO_AUTHORED_BY is the key of the Git trailer used to record co-authors of a commit.
+const TRAILER_CO_AUTHORED_BY string = "Co-authored-by"
+
+var re_trailer = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9\-]*)\s*:\s*(.*)$`)
+
+// Trailer is a single Git trailer (for example 'Signed-off-by: Jane Doe <jane@example.com>') in a commit message.
+type Trailer struct {
+	// Key is the name of the trailer, as it appears in the commit message.
+	Key string `json:"key"`
+	// Value is the value of the trailer.
+	Value string `json:"value"`
+}
+
+// CoAuthor is a co-author of a commit, derived from a 'Co-authored-by:' trailer.
+type CoAuthor struct {
+	// Name is the name of the co-author.
+	Name string `json:"name"`
+	// Email is the email address of the co-author, if present.
+	Email string `json:"email,omitempty"`
+}
+
+// parseTrailers returns the list of Git trailers in 'message'. Trailers are only read from the last paragraph of
+// a message, which must not be the first paragraph (the subject), and every line in that paragraph must be either
+// a trailer or the (indented) continuation of a trailer.
+func parseTrailers(message string) []*Trailer {
+
+	trailers := make([]*Trailer, 0)
+
+	message = strings.TrimRight(strings.ReplaceAll(message, "\r\n", "\n"), "\n ")
+	paragraphs := strings.Split(message, "\n\n")
+
+	if len(paragraphs) < 2 {
+		return trailers
+	}
+
+	last := strings.Trim(paragraphs[len(paragraphs)-1], "\n")
+
+	for _, ln := range strings.Split(last, "\n") {
+
+		if ln == "" {
+			return make([]*Trailer, 0)
+		}
+
+		if (strings.HasPrefix(ln, " ") || strings.HasPrefix(ln, "\t")) && len(trailers) > 0 {
+			t := trailers[len(trailers)-1]
+			t.Value = t.Value + " " + strings.TrimSpace(ln)
+			continue
+		}
+
+		m := re_trailer.FindStringSubmatch(ln)
+
+		if m == nil {
+			return make([]*Trailer, 0)
+		}
+
+		t := &Trailer{
+			Key:   m[1],
+			Value: strings.TrimSpace(m[2]),
+		}
+
+		trailers = append(trailers, t)
+	}
+
+	return trailers
+}
+
+// trailerValues returns the values of all the trailers in 'trailers' matching 'key' (case-insensitive).
+func trailerValues(trailers []*Trailer, key string) []string {
+
+	values := make([]string, 0)
+
+	for _, t := range trailers {
+
+		if strings.EqualFold(t.Key, key) {
+			values = append(values, t.Value)
+		}
+	}
+
+	return values
+}
+
+// parseCoAuthors returns the list of co-authors derived from the 'Co-authored-by:' trailers in 'trailers'.
+func parseCoAuthors(trailers []*Trailer) []*CoAuthor {
+
+	co_authors := make([]*CoAuthor, 0)
+
+	for _, v := range trailerValues(trailers, TRAILER_CO_AUTHORED_BY) {
+
+		addr, err := mail.ParseAddress(v)
+
+		if err != nil {
+			co_authors = append(co_authors, &CoAuthor{Name: v})
+			continue
+		}
+
+		co_authors = append(co_authors, &CoAuthor{Name: addr.Name, Email: addr.Address})
+	}
+
+	return co_authors
+}
@@ -0,0 +1,51 @@
+package github
+
+import (
+	"testing"
+)
+
+func TestParseTrailers(t *testing.T) {
+
+	msg := `update names for SFO terminals
+
+Some longer description of the change.
+
+WOF-Source: sfomuseum
+Co-authored-by: Jane Doe <jane@example.com>
+Signed-off-by: sfomuseumbot
+  <devnull@localhost>
+`
+
+	trailers := parseTrailers(msg)
+
+	if len(trailers) != 3 {
+		t.Fatalf("Unexpected trailer count: %d", len(trailers))
+	}
+
+	if trailers[0].Key != "WOF-Source" || trailers[0].Value != "sfomuseum" {
+		t.Fatalf("Unexpected first trailer: %s %s", trailers[0].Key, trailers[0].Value)
+	}
+
+	if trailers[2].Value != "sfomuseumbot <devnull@localhost>" {
+		t.Fatalf("Unexpected continuation value: %s", trailers[2].Value)
+	}
+
+	co_authors := parseCoAuthors(trailers)
+
+	if len(co_authors) != 1 || co_authors[0].Name != "Jane Doe" || co_authors[0].Email != "jane@example.com" {
+		t.Fatalf("Unexpected co-authors: %v", co_authors)
+	}
+
+	not_trailers := []string{
+		"Fixes: the subject line is not a trailer",
+		"update names\n\nThis is a description: not a trailer\nbecause of this line",
+		"append SWIM data for 20200521",
+	}
+
+	for _, msg := range not_trailers {
+
+		if len(parseTrailers(msg)) != 0 {
+			t.Fatalf("Expected no trailers for '%s'", msg)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubaudit", NewGitHubAuditTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubAuditTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub
+// `branch_protection_rule` and `deploy_key` webhook messages in to before and after records of repository settings changes.
+// The vendored version of go-github does not define repository ruleset events so they are not supported.
+type GitHubAuditTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of target (branch protection rule name or deploy key title) patterns to process. If empty all targets are processed.
+	targets []string
+	// The list of field patterns to include in the final output. If empty all fields are included.
+	fields []string
+}
+
+// auditEvents defines how `branch_protection_rule` and `deploy_key` events are handled by the `GitHubAuditTransformation`.
+var auditEvents = &eventHandler[*AuditRecord]{
+	decoders: map[string]eventDecoder[*AuditRecord]{
+		"branch_protection_rule": decodeEventWithBody(newAuditRecordFromBranchProtectionRule),
+		"deploy_key":             decodeEventWithBody(newAuditRecordFromDeployKey),
+	},
+	rule_field_names: auditRuleFieldNames,
+	rule_fields:      auditRuleFields,
+	rows:             auditRows,
+}
+
+// NewGitHubAuditTransformation() creates a new `GitHubAuditTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubaudit://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "branch_protection_rule", "deploy_key" or "infer" to infer the event type from each message. Default is "branch_protection_rule".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process: "created", "edited" or "deleted". Default is all actions.
+// * `?target` Zero or more `path.Match` patterns for the branch protection rule names or deploy key titles to process. Default is all targets.
+// * `?field` Zero or more `path.Match` patterns for the changed fields to include in the final output, for example "required_*". Default is all fields.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed, or where every change is excluded, cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubAuditTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "branch_protection_rule", auditEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubAuditTransformation{
+		eventParams: params,
+		actions:     parseListParam(q, "action"),
+		targets:     parseListParam(q, "target"),
+		fields:      parseListParam(q, "field"),
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `branch_protection_rule` or `deploy_key` webhook message) in to
+// CSV data, with one row for each change, containing: the name of the repository, the name of the event, the action, the target,
+// the field, the (JSON-encoded) before and after values and the user who made the change. If 'p' was created with `?format=json`
+// or `?format=ndjson` the output will be a JSON-encoded `AuditRecord`.
+func (p *GitHubAuditTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, auditEvents, p.filter, body)
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' does not match the action and target
+// filters used to create 'p'. Otherwise it removes the changes in 'rec' which do not match the field filters used to create
+// 'p' and returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if there are no remaining changes, or nil.
+func (p *GitHubAuditTransformation) filter(rec *AuditRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	switch {
+	case !matchesPattern(rec.Action, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case !matchesPattern(rec.Target, p.targets):
+		msg = fmt.Sprintf("Halt (target %s)", rec.Target)
+	default:
+
+		rec.Changes = filterAuditChanges(rec.Changes, p.fields)
+
+		if len(rec.Changes) > 0 {
+			return nil
+		}
+
+		msg = "Halt (no changes)"
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
@@ -0,0 +1,161 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubAuditTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri      string
+		msg      string
+		expected string
+	}{
+		{
+			"githubaudit://",
+			"fixtures/events/branch_protection_rule.json",
+			"sfomuseum-data-flights-2020-05,branch_protection_rule,edited,main,admin_enforced,false,true,thisisaaronland\n" +
+				`sfomuseum-data-flights-2020-05,branch_protection_rule,edited,main,required_status_checks,"[""build""]","[""build"",""validate-geojson""]",thisisaaronland` + "\n",
+		},
+		{
+			"githubaudit://?field=required_*",
+			"fixtures/events/branch_protection_rule.json",
+			`sfomuseum-data-flights-2020-05,branch_protection_rule,edited,main,required_status_checks,"[""build""]","[""build"",""validate-geojson""]",thisisaaronland` + "\n",
+		},
+		{
+			"githubaudit://?event=deploy_key",
+			"fixtures/events/deploy_key.json",
+			`sfomuseum-data-flights-2020-05,deploy_key,created,webhookd-deploy,key,,"{""id"":83412077,""key"":""ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDq2W6cPfvY0eXr1mXb1Jb9u3kR0xg0e1Z9H5mQe7c1K"",""url"":""https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/keys/83412077"",""title"":""webhookd-deploy"",""verified"":true,""created_at"":""2023-06-06T09:20:31Z"",""read_only"":true}",thisisaaronland` + "\n",
+		},
+		{
+			"githubaudit://?event=infer&min_confidence=medium",
+			"fixtures/events/deploy_key.json",
+			`sfomuseum-data-flights-2020-05,deploy_key,created,webhookd-deploy,key,,"{""id"":83412077,""key"":""ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDq2W6cPfvY0eXr1mXb1Jb9u3kR0xg0e1Z9H5mQe7c1K"",""url"":""https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/keys/83412077"",""title"":""webhookd-deploy"",""verified"":true,""created_at"":""2023-06-06T09:20:31Z"",""read_only"":true}",thisisaaronland` + "\n",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		body := readFixture(t, test.msg)
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		if string(rsp) != test.expected {
+			t.Fatalf("Unexpected output for %s: '%s'", test.uri, string(rsp))
+		}
+	}
+}
+
+func TestGitHubAuditTransformationWithJSON(t *testing.T) {
+
+	ctx := context.Background()
+
+	body := readFixture(t, "fixtures/events/branch_protection_rule.json")
+	deleted := bytes.Replace(body, []byte(`"action": "edited"`), []byte(`"action": "deleted"`), 1)
+
+	tests := []struct {
+		body   []byte
+		field  string
+		before bool
+		after  bool
+	}{
+		{body, "admin_enforced", true, true},
+		{deleted, "rule", true, false},
+	}
+
+	tr, err := transformation.NewTransformation(ctx, "githubaudit://?format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	for _, test := range tests {
+
+		rsp, err2 := tr.Transform(ctx, test.body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform message, %v", err2)
+		}
+
+		var rec AuditRecord
+
+		err = json.Unmarshal(rsp, &rec)
+
+		if err != nil {
+			t.Fatalf("Failed to unmarshal record, %v", err)
+		}
+
+		if rec.Schema != AUDIT_SCHEMA || rec.TargetID != 21796960 {
+			t.Fatalf("Unexpected record: %v", rec)
+		}
+
+		c := rec.Changes[0]
+
+		if c.Field != test.field || (len(c.Before) > 0) != test.before || (len(c.After) > 0) != test.after {
+			t.Fatalf("Unexpected change for %s: %s", rec.Action, string(rsp))
+		}
+	}
+}
+
+func TestGitHubAuditTransformationWithFilters(t *testing.T) {
+
+	body := readFixture(t, "fixtures/events/branch_protection_rule.json")
+	created := bytes.Replace(body, []byte(`"action": "edited"`), []byte(`"action": "created"`), 1)
+	release := bytes.Replace(body, []byte(`"name": "main"`), []byte(`"name": "release/*"`), 1)
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubaudit://?action=edited&target=main", body, false},
+		{"githubaudit://?action=edited", created, true},
+		{"githubaudit://?target=main", release, true},
+		{"githubaudit://?target=release/*", release, false},
+		{"githubaudit://?field=allow_*", body, true},
+		{"githubaudit://?field=rule", created, false},
+		{"githubaudit://?only_if=field%3D%3Dadmin_enforced", body, false},
+		{"githubaudit://?halt_if=actor%3D%3Dthisisaaronland", body, true},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubchecks", NewGitHubChecksTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubChecksTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub `status`,
+// `check_suite` and `check_run` webhook messages in to a normalized record of the result of a single context for a single commit.
+type GitHubChecksTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of context patterns to process. If empty all contexts are processed.
+	contexts []string
+	// The list of states (or conclusions) to process. If empty all states are processed.
+	states []string
+	// The list of normalized results to process. If empty all results are processed.
+	results []string
+	// The list of branch patterns to process. If empty all branches are processed.
+	branches []string
+}
+
+// checkEvents defines how `status`, `check_suite` and `check_run` events are handled by the `GitHubChecksTransformation`.
+var checkEvents = &eventHandler[*CheckRecord]{
+	decoders: map[string]eventDecoder[*CheckRecord]{
+		"status":      decodeEventAs(newCheckRecordFromStatus),
+		"check_suite": decodeEventAs(newCheckRecordFromSuite),
+		"check_run":   decodeEventAs(newCheckRecordFromRun),
+	},
+	rule_field_names: checkRuleFieldNames,
+	rule_fields:      checkRuleFields,
+	rows:             checkRows,
+}
+
+// NewGitHubChecksTransformation() creates a new `GitHubChecksTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubchecks://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "status", "check_suite", "check_run" or "infer" to infer the event type from each message. Default is "status".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process, for example "created", "completed" or "rerequested". `status` events have no action so they are never processed if this parameter is present. Default is all actions.
+// * `?context` Zero or more `path.Match` patterns for the status context, check run name or check suite app name to process, for example "ci/*". Default is all contexts.
+// * `?state` Zero or more commit status states or check conclusions to process, for example "failure", "error" or "timed_out". Checks which have not completed have the state "queued" or "in_progress". Default is all states.
+// * `?result` Zero or more normalized results to process: "green", "red", "pending" or "neutral". Default is all results.
+// * `?branch` Zero or more `path.Match` patterns for the branches to process. Events are processed if any of the branches the commit belongs to matches. Default is all branches.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubChecksTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "status", checkEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubChecksTransformation{
+		eventParams: params,
+		actions:     parseListParam(q, "action"),
+		contexts:    parseListParam(q, "context"),
+		states:      parseListParam(q, "state"),
+		results:     parseListParam(q, "result"),
+		branches:    parseListParam(q, "branch"),
+	}
+
+	for _, r := range p.results {
+
+		switch r {
+		case RESULT_GREEN, RESULT_RED, RESULT_PENDING, RESULT_NEUTRAL:
+			// pass
+		default:
+			return nil, fmt.Errorf("Invalid ?result= parameter '%s'", r)
+		}
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `status`, `check_suite` or `check_run` webhook message) in to
+// CSV data containing: the name of the repository, the commit hash, the context, the normalized result, the state (or conclusion)
+// and the details URL. If 'p' was created with `?format=json` or `?format=ndjson` the output will be a JSON-encoded `CheckRecord`.
+func (p *GitHubChecksTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, checkEvents, p.filter, body)
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' does not match the action, context,
+// state, result and branch filters used to create 'p'. Otherwise it returns nil.
+func (p *GitHubChecksTransformation) filter(rec *CheckRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	switch {
+	case !matchesPattern(rec.Action, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case !matchesPattern(rec.Context, p.contexts):
+		msg = fmt.Sprintf("Halt (context %s)", rec.Context)
+	case !matchesPattern(rec.State, p.states):
+		msg = fmt.Sprintf("Halt (state %s)", rec.State)
+	case !matchesPattern(rec.Result, p.results):
+		msg = fmt.Sprintf("Halt (result %s)", rec.Result)
+	case !matchesAnyBranch(rec.Branches, p.branches):
+		msg = "Halt (branch)"
+	default:
+		return nil
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
@@ -0,0 +1,162 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubChecksTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri      string
+		msg      string
+		expected string
+	}{
+		{
+			"githubchecks://",
+			"fixtures/events/status.json",
+			"sfomuseum-data-flights-2020-05,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,ci/validate-wof,red,failure,https://ci.sfomuseum.org/builds/4211\n",
+		},
+		{
+			"githubchecks://?event=check_suite",
+			"fixtures/events/check_suite.json",
+			"sfomuseum-data-flights-2020-05,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,GitHub Actions,green,success,https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/check-suites/13337760291\n",
+		},
+		{
+			"githubchecks://?event=check_run",
+			"fixtures/events/check_run.json",
+			"sfomuseum-data-flights-2020-05,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,validate-geojson,red,failure,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/runs/14003118842\n",
+		},
+		{
+			"githubchecks://?event=infer",
+			"fixtures/events/check_run.json",
+			"sfomuseum-data-flights-2020-05,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,validate-geojson,red,failure,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/runs/14003118842\n",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		body := readFixture(t, test.msg)
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		if string(rsp) != test.expected {
+			t.Fatalf("Unexpected output for %s: '%s'", test.msg, string(rsp))
+		}
+	}
+}
+
+func TestGitHubChecksTransformationWithJSON(t *testing.T) {
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubchecks://?event=infer&format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, readFixture(t, "fixtures/events/status.json"))
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec CheckRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	if rec.Schema != CHECK_SCHEMA || rec.Event != "status" || rec.Result != RESULT_RED || rec.Description != "Validation failed for 3 records" {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+
+	if len(rec.Branches) != 1 || rec.Branches[0] != "main" {
+		t.Fatalf("Unexpected branches: %v", rec.Branches)
+	}
+}
+
+func TestGitHubChecksTransformationWithFilters(t *testing.T) {
+
+	status := readFixture(t, "fixtures/events/status.json")
+	pending := bytes.Replace(status, []byte(`"state": "failure"`), []byte(`"state": "pending"`), 1)
+
+	check_run := readFixture(t, "fixtures/events/check_run.json")
+	in_progress := bytes.Replace(check_run, []byte(`"status": "completed",
+    "conclusion": "failure"`), []byte(`"status": "in_progress",
+    "conclusion": null`), 1)
+	skipped := bytes.Replace(check_run, []byte(`"conclusion": "failure"`), []byte(`"conclusion": "skipped"`), 1)
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubchecks://?context=ci/*&state=failure,error&result=red&branch=main", status, false},
+		{"githubchecks://?context=travis/*", status, true},
+		{"githubchecks://?result=red", pending, true},
+		{"githubchecks://?result=pending&state=pending", pending, false},
+		{"githubchecks://?branch=gh-pages", status, true},
+		{"githubchecks://?action=completed", status, true},
+		{"githubchecks://?event=check_run&action=completed&context=validate-*", check_run, false},
+		{"githubchecks://?event=check_run&result=red", in_progress, true},
+		{"githubchecks://?event=check_run&state=in_progress&result=pending", in_progress, false},
+		{"githubchecks://?event=check_run&result=neutral", skipped, false},
+		{"githubchecks://?event=check_run&only_if=result%3D%3Dgreen", check_run, true},
+		{"githubchecks://?event=check_run&halt_if=description%3D~failed", check_run, true},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+}
+
+func TestGitHubChecksTransformationWithInvalidResult(t *testing.T) {
+
+	ctx := context.Background()
+
+	_, err := transformation.NewTransformation(ctx, "githubchecks://?result=blue")
+
+	if err == nil {
+		t.Fatalf("Expected invalid ?result= parameter to fail")
+	}
+}
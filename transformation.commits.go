@@ -6,8 +6,8 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
-	"regexp"
 	"strconv"
 
 	gogithub "github.com/google/go-github/v48/github"
@@ -38,14 +38,26 @@ type GitHubCommitsTransformation struct {
 	ExcludeModifications bool
 	// ExcludeDeletions is a boolean flag to exclude deleted files from the final output.
 	ExcludeDeletions bool
+	// IncludeURLs is a boolean flag to include the raw, HTML and API URLs for each file in the final output.
+	IncludeURLs bool
+	// ExcludeRenames is a boolean flag to exclude renamed files from the final output. Renames are only reported if rename detection is enabled.
+	ExcludeRenames bool
 	// A boolean flag signaling the commit message should be prepended to the top of the final output in the form of '#message {COMMIT_MESSAGE}'
 	prepend_message bool
 	// A boolean flag signaling the commit author should be prepended to the top of the final output in the form of '#author {COMMIT_AUTHOR}'
 	prepend_author bool
-	// An optional regular expression that will be compared to the commit message; if it matches the transformer will return an error with code `webhookd.HaltEvent`
-	halt_on_message *regexp.Regexp
-	// An optional regular expression that will be compared to the commit author; if it matches the transformer will return an error with code `webhookd.HaltEvent`
-	halt_on_author *regexp.Regexp
+	// The set of rules used to determine whether the transformer should return an error with code `webhookd.HaltEvent`. This includes the `?halt_on_message` and `?halt_on_author` parameters.
+	rules *RuleSet
+	// The format of the final output. Valid options are `FORMAT_CSV`, `FORMAT_JSON` and `FORMAT_NDJSON`.
+	format string
+	// The conditions under which the GitHub compare API will be used to derive the list of changed files. Valid options are `COMPARE_NEVER`, `COMPARE_AUTO` and `COMPARE_ALWAYS`.
+	compare string
+	// An optional GitHub API client used to derive the list of changed files.
+	client *gogithub.Client
+	// A boolean flag signaling that renamed files should be reported as a single change rather than a removal and an addition.
+	detect_renames bool
+	// The list of columns to include in CSV output.
+	columns []string
 }
 
 // NewGitHubCommitsTransformation() creates a new `GitHubCommitsTransformation` instance, configured by 'uri'
@@ -57,10 +69,20 @@ type GitHubCommitsTransformation struct {
 // * `?exclude_additions` An optional boolean value to exclude newly added files from the final output.
 // * `?exclude_modifications` An optional boolean value to exclude update (modified) files from the final output.
 // * `?exclude_deletions` An optional boolean value to exclude deleted files from the final output.
+// * `?exclude_renames` An optional boolean value to exclude renamed files from the final output.
+// * `?include_urls` An optional boolean value to include the raw, HTML and API URLs for each file in the final output. In CSV output these are appended as additional columns.
+// * `?columns` An optional comma-separated list of columns to include in CSV output. If present this supersedes the default columns. Valid options are: status, action, commit, change_commit, repo, path, previous_path, raw_url, html_url, api_url, message, author, co_authors and trailer.{KEY}.
 // * `?prepend_message` An optional boolean value to prepend the commit message to the final output. This takes the form of '#message,{COMMIT_MESSAGE},'
 // * `?prepend_author` An optional boolean value to prepend the name of the commit author to the final output. This takes the form of '#author,{COMMIT_AUTHOR},'
 // * `?halt_on_message` An optional regular expression that will be compared to the commit message; if it matches the transformer will return an error with code `webhookd.HaltEvent`
 // * `?halt_on_author` An optional regular expression that will be compared to the commit author; if it matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+// * `?compare` An optional string indicating when the GitHub compare API should be used to derive the complete list of changed files. Valid options are: never, auto (only when the push appears to have been truncated), always. Default is never.
+// * `?api_token` An optional GitHub API access token to use with the compare API.
+// * `?api_base_url` An optional base URL for the GitHub API. Default is `DEFAULT_API_BASE_URL`.
+// * `?detect_renames` An optional boolean value to report renamed files as a single change. If a GitHub API token or base URL is present renames are derived from the compare API, otherwise files with the same base name that were removed and added in the same commit are assumed to have been renamed. When enabled CSV output takes the form of: status (A, M, D, R), commit hash, repository name, path, previous path.
 func NewGitHubCommitsTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
 
 	u, err := url.Parse(uri)
@@ -74,15 +96,33 @@ func NewGitHubCommitsTransformation(ctx context.Context, uri string) (webhookd.W
 	q_additions := q.Get("exclude_additions")
 	q_modifications := q.Get("exclude_modifications")
 	q_deletions := q.Get("exclude_deletions")
+	q_renames := q.Get("exclude_renames")
+	q_detect_renames := q.Get("detect_renames")
+	q_include_urls := q.Get("include_urls")
 	q_message := q.Get("prepend_message")
 	q_author := q.Get("prepend_author")
 
 	q_halt_on_message := q.Get("halt_on_message")
 	q_halt_on_author := q.Get("halt_on_author")
 
+	format, err := parseFormat(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compare, err := parseCompareMode(q.Get("compare"))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse ?compare= parameter, %w", err)
+	}
+
 	exclude_additions := false
 	exclude_modifications := false
 	exclude_deletions := false
+	exclude_renames := false
+	detect_renames := false
+	include_urls := false
 
 	prepend_message := false
 	prepend_author := false
@@ -120,6 +160,39 @@ func NewGitHubCommitsTransformation(ctx context.Context, uri string) (webhookd.W
 		exclude_deletions = v
 	}
 
+	if q_renames != "" {
+
+		v, err := strconv.ParseBool(q_renames)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse '%s', %w", q_renames, err)
+		}
+
+		exclude_renames = v
+	}
+
+	if q_detect_renames != "" {
+
+		v, err := strconv.ParseBool(q_detect_renames)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse '%s', %w", q_detect_renames, err)
+		}
+
+		detect_renames = v
+	}
+
+	if q_include_urls != "" {
+
+		v, err := strconv.ParseBool(q_include_urls)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse '%s', %w", q_include_urls, err)
+		}
+
+		include_urls = v
+	}
+
 	if q_message != "" {
 
 		v, err := strconv.ParseBool(q_message)
@@ -146,37 +219,109 @@ func NewGitHubCommitsTransformation(ctx context.Context, uri string) (webhookd.W
 		ExcludeAdditions:     exclude_additions,
 		ExcludeModifications: exclude_modifications,
 		ExcludeDeletions:     exclude_deletions,
+		ExcludeRenames:       exclude_renames,
+		IncludeURLs:          include_urls,
 		prepend_message:      prepend_message,
 		prepend_author:       prepend_author,
+		format:               format,
+		compare:              compare,
+		detect_renames:       detect_renames,
+		columns:              defaultColumns,
+	}
+
+	if detect_renames {
+		p.columns = renameColumns
+	}
+
+	if include_urls {
+		columns := make([]string, 0)
+		columns = append(columns, p.columns...)
+		columns = append(columns, urlColumns...)
+		p.columns = columns
+	}
+
+	if q.Has("columns") {
+
+		columns, err := parseColumns(parseListParam(q, "columns"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?columns= parameter, %w", err)
+		}
+
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("Failed to parse ?columns= parameter, no columns specified")
+		}
+
+		p.columns = columns
+
+		if hasURLColumns(columns) {
+			p.IncludeURLs = true
+		}
+	}
+
+	api_base_url := q.Get("api_base_url")
+	api_token := q.Get("api_token")
+
+	use_api := compare != COMPARE_NEVER
+
+	if detect_renames && (api_base_url != "" || api_token != "") {
+		use_api = true
+	}
+
+	if use_api {
+
+		client, err := newAPIClient(api_base_url, api_token)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create API client, %w", err)
+		}
+
+		p.client = client
+	}
+
+	rules, err := NewRuleSetFromQuery(q)
+
+	if err != nil {
+		return nil, err
 	}
 
 	if q_halt_on_message != "" {
 
-		r, err := regexp.Compile(q_halt_on_message)
+		r, err := ParseRule(fmt.Sprintf("head_message%s%s", OPERATOR_MATCHES, q_halt_on_message))
 
 		if err != nil {
 			return nil, fmt.Errorf("Failed to parse ?halt_on_message= parameter, %w", err)
 		}
 
-		p.halt_on_message = r
+		rules.Halt = append(rules.Halt, r)
 	}
 
 	if q_halt_on_author != "" {
 
-		r, err := regexp.Compile(q_halt_on_author)
+		r, err := ParseRule(fmt.Sprintf("head_author%s%s", OPERATOR_MATCHES, q_halt_on_author))
 
 		if err != nil {
 			return nil, fmt.Errorf("Failed to parse ?halt_on_author= parameter, %w", err)
 		}
 
-		p.halt_on_author = r
+		rules.Halt = append(rules.Halt, r)
+	}
+
+	err = rules.Validate(pushRuleFieldNames)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid rules, %w", err)
 	}
 
+	p.rules = rules
+
 	return &p, nil
 }
 
 // Transform() transforms 'body' (which is assumed to be a GitHub commit webhook message) in to CSV data containing:
-// the commit hash, the name of the repository and the path to the file commited.
+// the commit hash, the name of the repository and the path to the file commited. If 'p' was created with `?format=json`
+// then the output will be a JSON-encoded `PushRecord`. If 'p' was created with `?format=ndjson` then the output will be
+// one JSON-encoded `Change` per line.
 func (p *GitHubCommitsTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
 
 	select {
@@ -195,14 +340,64 @@ func (p *GitHubCommitsTransformation) Transform(ctx context.Context, body []byte
 		return nil, err
 	}
 
-	if p.halt_on_message != nil && p.halt_on_message.MatchString(*event.HeadCommit.Message) {
-		err := &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: "Halt"}
-		return nil, err
+	// Rules that don't depend on the list of changed files are evaluated before (possibly) calling the GitHub compare API
+
+	change_rules, event_rules := p.rules.Partition(pushChangeRuleFieldNames)
+
+	halt_err := event_rules.Evaluate(pushRuleFields(&event, nil))
+
+	if halt_err != nil {
+		return nil, halt_err
 	}
 
-	if p.halt_on_author != nil && p.halt_on_author.MatchString(*event.HeadCommit.Author.Name) {
-		err := &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: "Halt"}
-		return nil, err
+	changes := pushChanges(&event)
+
+	if p.useCompare(&event) {
+
+		cmp_changes, err := compareChanges(ctx, p.client, &event, p.detect_renames)
+
+		if err != nil {
+			err := &webhookd.WebhookError{Code: http.StatusBadGateway, Message: err.Error()}
+			return nil, err
+		}
+
+		changes = cmp_changes
+
+	} else if p.detect_renames {
+		changes = pairRenames(changes)
+	}
+
+	halt_err = change_rules.Evaluate(pushRuleFields(&event, changes))
+
+	if halt_err != nil {
+		return nil, halt_err
+	}
+
+	changes = filterChanges(changes, p.ExcludeAdditions, p.ExcludeModifications, p.ExcludeDeletions, p.ExcludeRenames)
+
+	if p.IncludeURLs {
+
+		for _, ch := range changes {
+
+			urls, err := changeFileURLs(&event, ch)
+
+			if err != nil {
+				err := &webhookd.WebhookError{Code: 999, Message: err.Error()}
+				return nil, err
+			}
+
+			ch.FileURLs = urls
+		}
+	}
+
+	switch p.format {
+	case FORMAT_JSON:
+		rec := newPushRecord(&event, changes)
+		return marshalJSON(rec)
+	case FORMAT_NDJSON:
+		return marshalNDJSON(changes)
+	default:
+		// pass
 	}
 
 	buf := new(bytes.Buffer)
@@ -210,43 +405,51 @@ func (p *GitHubCommitsTransformation) Transform(ctx context.Context, body []byte
 
 	if p.prepend_message {
 		v := fmt.Sprintf("#message %s", *event.HeadCommit.Message)
-		wr.Write([]string{v, "", ""})
+		row := make([]string, len(p.columns))
+		row[0] = v
+		wr.Write(row)
 	}
 
 	if p.prepend_author {
 		v := fmt.Sprintf("#author %s", *event.HeadCommit.Author.Name)
-		wr.Write([]string{v, "", ""})
+		row := make([]string, len(p.columns))
+		row[0] = v
+		wr.Write(row)
 	}
 
-	repo := event.Repo
-	repo_name := *repo.Name
-	commit_hash := *event.HeadCommit.ID
+	for _, ch := range changes {
+		row := changeRow(&event, ch, p.columns)
+		wr.Write(row)
+	}
 
-	for _, c := range event.Commits {
+	wr.Flush()
 
-		if !p.ExcludeAdditions {
-			for _, path := range c.Added {
-				commit := []string{commit_hash, repo_name, path}
-				wr.Write(commit)
-			}
-		}
+	return buf.Bytes(), nil
+}
 
-		if !p.ExcludeModifications {
-			for _, path := range c.Modified {
-				commit := []string{commit_hash, repo_name, path}
-				wr.Write(commit)
-			}
-		}
+// useCompare returns a boolean value indicating whether the GitHub compare API should be used to derive the list of changed files for 'event'.
+// The compare API is always used for rename detection when 'p' has been configured with an API client.
+func (p *GitHubCommitsTransformation) useCompare(event *gogithub.PushEvent) bool {
 
-		if !p.ExcludeDeletions {
-			for _, path := range c.Removed {
-				commit := []string{commit_hash, repo_name, path}
-				wr.Write(commit)
-			}
-		}
+	if p.client == nil {
+		return false
 	}
 
-	wr.Flush()
+	if !canComparePush(event) {
+		return false
+	}
 
-	return buf.Bytes(), nil
+	switch p.compare {
+	case COMPARE_ALWAYS:
+		return true
+	case COMPARE_AUTO:
+
+		if isTruncatedPush(event) {
+			return true
+		}
+	default:
+		// pass
+	}
+
+	return p.detect_renames
 }
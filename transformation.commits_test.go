@@ -1,13 +1,20 @@
 package github
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/whosonfirst/go-webhookd/v3"
@@ -175,3 +182,828 @@ func TestGitHubCommitsTransformationWithHalt(t *testing.T) {
 		t.Fatalf("Expected halt event")
 	}
 }
+
+func TestGitHubCommitsTransformationWithJSON(t *testing.T) {
+
+	expected_changes := 1607
+	expected_message := "append SWIM data for 20200521"
+
+	msg := "fixtures/events/flights.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubcommits://?format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec PushRecord
+
+	err = json.Unmarshal(data, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal JSON output, %v", err)
+	}
+
+	if rec.Schema != PUSH_SCHEMA || rec.Version != PUSH_SCHEMA_VERSION {
+		t.Fatalf("Unexpected schema: %s (%d)", rec.Schema, rec.Version)
+	}
+
+	if rec.Message != expected_message {
+		t.Fatalf("Unexpected message '%s'", rec.Message)
+	}
+
+	if len(rec.Changes) != expected_changes {
+		t.Fatalf("Unexpected change count: %d", len(rec.Changes))
+	}
+}
+
+func TestGitHubCommitsTransformationWithNDJSON(t *testing.T) {
+
+	expected_changes := 1607
+
+	msg := "fixtures/events/flights.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubcommits://?format=ndjson")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	count := 0
+
+	for scanner.Scan() {
+
+		var ch Change
+
+		err := json.Unmarshal(scanner.Bytes(), &ch)
+
+		if err != nil {
+			t.Fatalf("Failed to unmarshal line %d, %v", count, err)
+		}
+
+		if ch.Path == "" {
+			t.Fatalf("Missing path for line %d", count)
+		}
+
+		count += 1
+	}
+
+	if count != expected_changes {
+		t.Fatalf("Unexpected line count: %d", count)
+	}
+}
+
+// newCompareTestServer returns a new `httptest.Server` instance that acts as a stand-in for the GitHub compare and
+// commit APIs, requiring requests to include 'token'. Each comparison contains 'commits' commits each of which changes
+// 'files_per_commit' files. Like GitHub the compare API paginates commits and only includes (at most `MAX_COMPARE_FILES`)
+// files in the first page of results, and the commit API paginates files.
+func newCompareTestServer(commits int, files_per_commit int, token string) *httptest.Server {
+
+	compare_prefix := "/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/compare/"
+	commit_prefix := "/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/commits/"
+
+	commit_hash := func(i int) string {
+		return fmt.Sprintf("%040d", i)
+	}
+
+	commit_file := func(i int, j int) map[string]string {
+
+		return map[string]string{
+			"filename": fmt.Sprintf("data/%d/%d.geojson", i, j),
+			"status":   "added",
+		}
+	}
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+
+		if req.Header.Get("Authorization") != fmt.Sprintf("token %s", token) {
+			http.Error(rsp, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		page := 1
+		per_page := 30
+
+		q := req.URL.Query()
+
+		for k, ptr := range map[string]*int{"page": &page, "per_page": &per_page} {
+
+			if q.Get(k) == "" {
+				continue
+			}
+
+			v, err := strconv.Atoi(q.Get(k))
+
+			if err != nil {
+				http.Error(rsp, "Bad request", http.StatusBadRequest)
+				return
+			}
+
+			*ptr = v
+		}
+
+		var count int
+		var rec map[string]interface{}
+
+		switch {
+		case strings.HasPrefix(req.URL.Path, compare_prefix):
+
+			count = commits
+
+			page_commits := make([]map[string]interface{}, 0)
+
+			for i := (page - 1) * per_page; i < page*per_page && i < count; i++ {
+
+				c := map[string]interface{}{
+					"sha": commit_hash(i),
+					"commit": map[string]interface{}{
+						"message": fmt.Sprintf("Commit %d", i),
+					},
+				}
+
+				page_commits = append(page_commits, c)
+			}
+
+			files := make([]map[string]string, 0)
+
+			if page == 1 {
+
+				for i := 0; i < commits && len(files) < MAX_COMPARE_FILES; i++ {
+
+					for j := 0; j < files_per_commit && len(files) < MAX_COMPARE_FILES; j++ {
+						files = append(files, commit_file(i, j))
+					}
+				}
+			}
+
+			rec = map[string]interface{}{
+				"total_commits": commits,
+				"commits":       page_commits,
+				"files":         files,
+			}
+
+		case strings.HasPrefix(req.URL.Path, commit_prefix):
+
+			idx := -1
+			sha := strings.TrimPrefix(req.URL.Path, commit_prefix)
+
+			for i := 0; i < commits; i++ {
+
+				if commit_hash(i) == sha {
+					idx = i
+					break
+				}
+			}
+
+			if idx == -1 {
+				http.Error(rsp, "Not found", http.StatusNotFound)
+				return
+			}
+
+			count = files_per_commit
+
+			files := make([]map[string]string, 0)
+
+			for j := (page - 1) * per_page; j < page*per_page && j < count; j++ {
+				files = append(files, commit_file(idx, j))
+			}
+
+			rec = map[string]interface{}{
+				"sha":   sha,
+				"files": files,
+			}
+
+		default:
+			http.Error(rsp, "Not found", http.StatusNotFound)
+			return
+		}
+
+		if page*per_page < count {
+			next := *req.URL
+			next_q := next.Query()
+			next_q.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = next_q.Encode()
+			rsp.Header().Set("Link", fmt.Sprintf("<http://%s%s>; rel=\"next\"", req.Host, next.String()))
+		}
+
+		rsp.Header().Set("Content-Type", "application/json")
+
+		enc := json.NewEncoder(rsp)
+		enc.Encode(rec)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+func TestGitHubCommitsTransformationWithCompare(t *testing.T) {
+
+	token := "s33kret"
+	expected_rows := 250
+
+	server := newCompareTestServer(expected_rows, 1, token)
+	defer server.Close()
+
+	msg := "fixtures/events/truncated.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	q := url.Values{}
+	q.Set("compare", "auto")
+	q.Set("api_token", token)
+	q.Set("api_base_url", server.URL)
+
+	tr, err := transformation.NewTransformation(ctx, "githubcommits://?"+q.Encode())
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	csv_r := csv.NewReader(bytes.NewReader(data))
+
+	rows, err := csv_r.ReadAll()
+
+	if err != nil {
+		t.Fatalf("Failed to read CSV data, %v", err)
+	}
+
+	if len(rows) != expected_rows {
+		t.Fatalf("Unexpected row count: %d", len(rows))
+	}
+
+	// Non-truncated pushes should not use the compare API in "auto" mode
+
+	msg = "fixtures/events/flights.json"
+	fh2, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh2.Close()
+
+	body, err = io.ReadAll(fh2)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	data, err2 = tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	csv_r = csv.NewReader(bytes.NewReader(data))
+
+	rows, err = csv_r.ReadAll()
+
+	if err != nil {
+		t.Fatalf("Failed to read CSV data, %v", err)
+	}
+
+	if len(rows) != 1607 {
+		t.Fatalf("Unexpected row count: %d", len(rows))
+	}
+}
+
+func TestGitHubCommitsTransformationWithCompareTruncatedFiles(t *testing.T) {
+
+	token := "s33kret"
+
+	commits := 4
+	files_per_commit := 150
+
+	server := newCompareTestServer(commits, files_per_commit, token)
+	defer server.Close()
+
+	body := readFixture(t, "fixtures/events/truncated.json")
+
+	ctx := context.Background()
+
+	q := url.Values{}
+	q.Set("compare", "always")
+	q.Set("api_token", token)
+	q.Set("api_base_url", server.URL)
+	q.Set("columns", "change_commit,path")
+
+	tr, err := transformation.NewTransformation(ctx, "githubcommits://?"+q.Encode())
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	csv_r := csv.NewReader(bytes.NewReader(data))
+
+	rows, err := csv_r.ReadAll()
+
+	if err != nil {
+		t.Fatalf("Failed to read CSV data, %v", err)
+	}
+
+	if len(rows) != commits*files_per_commit {
+		t.Fatalf("Unexpected row count: %d", len(rows))
+	}
+
+	seen := make(map[string]int)
+
+	for _, row := range rows {
+		seen[row[0]] += 1
+	}
+
+	if len(seen) != commits {
+		t.Fatalf("Unexpected commit count: %d", len(seen))
+	}
+
+	for hash, count := range seen {
+
+		if count != files_per_commit {
+			t.Fatalf("Unexpected file count for commit %s: %d", hash, count)
+		}
+	}
+}
+
+func TestGitHubCommitsTransformationWithCompareHalted(t *testing.T) {
+
+	token := "s33kret"
+	requests := 0
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		requests += 1
+		http.Error(rsp, "Not found", http.StatusNotFound)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	body := readFixture(t, "fixtures/events/truncated.json")
+
+	ctx := context.Background()
+
+	q := url.Values{}
+	q.Set("compare", "always")
+	q.Set("api_token", token)
+	q.Set("api_base_url", server.URL)
+	q.Set("halt_if", "branch==main")
+
+	tr, err := transformation.NewTransformation(ctx, "githubcommits://?"+q.Encode())
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	_, err2 := tr.Transform(ctx, body)
+
+	if err2 == nil || err2.Code != webhookd.HaltEvent {
+		t.Fatalf("Expected transformation to halt, %v", err2)
+	}
+
+	if requests != 0 {
+		t.Fatalf("Expected compare API not to be called for halted event, %d requests", requests)
+	}
+}
+
+func TestGitHubCommitsTransformationWithRenames(t *testing.T) {
+
+	expected_rows := [][]string{
+		{"R", "9b1c3a0e2f4d5b6a7c8d9e0f1a2b3c4d5e6f7a8b", "sfomuseum-data-flights-2020-05", "archive/171/316/450/9/1713164509.geojson", "data/171/316/450/9/1713164509.geojson"},
+		{"A", "9b1c3a0e2f4d5b6a7c8d9e0f1a2b3c4d5e6f7a8b", "sfomuseum-data-flights-2020-05", "data/171/316/451/9/1713164519.geojson", ""},
+		{"M", "9b1c3a0e2f4d5b6a7c8d9e0f1a2b3c4d5e6f7a8b", "sfomuseum-data-flights-2020-05", "data/171/316/448/1/1713164481.geojson", ""},
+		{"D", "9b1c3a0e2f4d5b6a7c8d9e0f1a2b3c4d5e6f7a8b", "sfomuseum-data-flights-2020-05", "data/171/316/483/5/1713164835.geojson", ""},
+	}
+
+	msg := "fixtures/events/rename.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubcommits://?detect_renames=true")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	csv_r := csv.NewReader(bytes.NewReader(data))
+
+	rows, err := csv_r.ReadAll()
+
+	if err != nil {
+		t.Fatalf("Failed to read CSV data, %v", err)
+	}
+
+	if len(rows) != len(expected_rows) {
+		t.Fatalf("Unexpected row count: %d", len(rows))
+	}
+
+	for idx, row := range rows {
+
+		if strings.Join(row, ",") != strings.Join(expected_rows[idx], ",") {
+			t.Fatalf("Unexpected row %d: %s", idx, strings.Join(row, ","))
+		}
+	}
+}
+
+func TestGitHubCommitsTransformationWithRenamesFromAPI(t *testing.T) {
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+
+		if req.URL.Path != "/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/compare/e3a18d4de60a5e50ca78ca1733238735ddfaef4c...9b1c3a0e2f4d5b6a7c8d9e0f1a2b3c4d5e6f7a8b" {
+			http.Error(rsp, "Not found", http.StatusNotFound)
+			return
+		}
+
+		files := []map[string]string{
+			{
+				"filename":          "archive/171/316/450/9/1713164509.geojson",
+				"previous_filename": "data/171/316/450/9/1713164509.geojson",
+				"status":            "renamed",
+			},
+			{
+				"filename": "data/171/316/448/1/1713164481.geojson",
+				"status":   "modified",
+			},
+		}
+
+		rsp.Header().Set("Content-Type", "application/json")
+
+		enc := json.NewEncoder(rsp)
+		enc.Encode(map[string]interface{}{"files": files})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	msg := "fixtures/events/rename.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	q := url.Values{}
+	q.Set("detect_renames", "true")
+	q.Set("api_base_url", server.URL)
+	q.Set("format", "json")
+
+	tr, err := transformation.NewTransformation(ctx, "githubcommits://?"+q.Encode())
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec PushRecord
+
+	err = json.Unmarshal(data, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal JSON output, %v", err)
+	}
+
+	if len(rec.Changes) != 2 {
+		t.Fatalf("Unexpected change count: %d", len(rec.Changes))
+	}
+
+	ch := rec.Changes[0]
+
+	if ch.Action != ACTION_RENAMED || ch.PreviousPath != "data/171/316/450/9/1713164509.geojson" {
+		t.Fatalf("Unexpected change: %s %s (%s)", ch.Action, ch.Path, ch.PreviousPath)
+	}
+}
+
+func TestGitHubCommitsTransformationWithRules(t *testing.T) {
+
+	tests := map[string]bool{
+		"halt_if=path*=data/171/316/450/9/*.geojson":               true,
+		"halt_if=added>2000":                                       false,
+		"only_if=branch==dev":                                      true,
+		"only_if=branch==main&only_if=full_name=~^sfomuseum-data/": false,
+		"halt_if=committer_email==noreply@github.com":              false,
+	}
+
+	msg := "fixtures/events/flights.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	for raw, expected_halt := range tests {
+
+		q, err := url.ParseQuery(raw)
+
+		if err != nil {
+			t.Fatalf("Failed to parse query '%s', %v", raw, err)
+		}
+
+		tr, err := transformation.NewTransformation(ctx, "githubcommits://?"+q.Encode())
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for '%s', %v", raw, err)
+		}
+
+		_, err2 := tr.Transform(ctx, body)
+
+		if expected_halt && (err2 == nil || err2.Code != webhookd.HaltEvent) {
+			t.Fatalf("Expected halt event for '%s'", raw)
+		}
+
+		if !expected_halt && err2 != nil {
+			t.Fatalf("Unexpected error for '%s', %v", raw, err2)
+		}
+	}
+
+	_, err = transformation.NewTransformation(ctx, "githubcommits://?halt_if=colour==red")
+
+	if err == nil {
+		t.Fatalf("Expected unknown rule field to fail")
+	}
+}
+
+func TestGitHubCommitsTransformationWithURLs(t *testing.T) {
+
+	expected_row := []string{
+		"e3a18d4de60a5e50ca78ca1733238735ddfaef4c",
+		"sfomuseum-data-flights-2020-05",
+		"data/171/316/253/1/1713162531-alt-swim-approach.geojson",
+		"https://raw.githubusercontent.com/sfomuseum-data/sfomuseum-data-flights-2020-05/e3a18d4de60a5e50ca78ca1733238735ddfaef4c/data/171/316/253/1/1713162531-alt-swim-approach.geojson",
+		"https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/blob/e3a18d4de60a5e50ca78ca1733238735ddfaef4c/data/171/316/253/1/1713162531-alt-swim-approach.geojson",
+		"https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/contents/data/171/316/253/1/1713162531-alt-swim-approach.geojson?ref=e3a18d4de60a5e50ca78ca1733238735ddfaef4c",
+	}
+
+	msg := "fixtures/events/flights.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubcommits://?include_urls=true")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	csv_r := csv.NewReader(bytes.NewReader(data))
+
+	rows, err := csv_r.ReadAll()
+
+	if err != nil {
+		t.Fatalf("Failed to read CSV data, %v", err)
+	}
+
+	found := false
+
+	for _, row := range rows {
+
+		if row[2] != expected_row[2] {
+			continue
+		}
+
+		if strings.Join(row, ",") != strings.Join(expected_row, ",") {
+			t.Fatalf("Unexpected row: %s", strings.Join(row, ","))
+		}
+
+		found = true
+		break
+	}
+
+	if !found {
+		t.Fatalf("Failed to find row for %s", expected_row[2])
+	}
+}
+
+func TestGitHubCommitsTransformationWithTrailers(t *testing.T) {
+
+	expected_row := "data/171/316/448/1/1713164481.geojson,sfomuseum,Jane Doe"
+
+	msg := "fixtures/events/trailers.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubcommits://?columns=path,trailer.wof-source,co_authors")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	csv_r := csv.NewReader(bytes.NewReader(data))
+
+	rows, err := csv_r.ReadAll()
+
+	if err != nil {
+		t.Fatalf("Failed to read CSV data, %v", err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("Unexpected row count: %d", len(rows))
+	}
+
+	if strings.Join(rows[0], ",") != expected_row {
+		t.Fatalf("Unexpected row: %s", strings.Join(rows[0], ","))
+	}
+
+	tr, err = transformation.NewTransformation(ctx, "githubcommits://?format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 = tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec PushRecord
+
+	err = json.Unmarshal(data, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal JSON output, %v", err)
+	}
+
+	if len(rec.Commits) != 1 || len(rec.Commits[0].Trailers) != 3 || len(rec.Commits[0].CoAuthors) != 1 {
+		t.Fatalf("Unexpected commits: %v", rec.Commits)
+	}
+
+	if len(rec.Changes[0].Trailers) != 3 {
+		t.Fatalf("Unexpected trailers for change: %v", rec.Changes[0].Trailers)
+	}
+
+	halt_tests := map[string]bool{
+		"halt_if=trailer.WOF-Source==sfomuseum":     true,
+		"only_if=co_author_email==jane@example.com": false,
+		"halt_if=trailer.signed-off-by=~^nobody":    false,
+	}
+
+	for raw, expected_halt := range halt_tests {
+
+		q, err := url.ParseQuery(raw)
+
+		if err != nil {
+			t.Fatalf("Failed to parse query '%s', %v", raw, err)
+		}
+
+		tr, err := transformation.NewTransformation(ctx, "githubcommits://?"+q.Encode())
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for '%s', %v", raw, err)
+		}
+
+		_, err2 := tr.Transform(ctx, body)
+
+		if expected_halt && (err2 == nil || err2.Code != webhookd.HaltEvent) {
+			t.Fatalf("Expected halt event for '%s'", raw)
+		}
+
+		if !expected_halt && err2 != nil {
+			t.Fatalf("Unexpected error for '%s', %v", raw, err2)
+		}
+	}
+}
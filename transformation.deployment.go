@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubdeployment", NewGitHubDeploymentTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubDeploymentTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub
+// `deployment` and `deployment_status` webhook messages in to deployment metadata.
+type GitHubDeploymentTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// The list of environment patterns to process. If empty all environments are processed.
+	environments []string
+	// The list of ref patterns to process. If empty all refs are processed.
+	refs []string
+	// The list of deployment status states to process. If empty all states are processed.
+	states []string
+}
+
+// deploymentEvents defines how `deployment` and `deployment_status` events are handled by the `GitHubDeploymentTransformation`.
+var deploymentEvents = &eventHandler[*DeploymentRecord]{
+	decoders: map[string]eventDecoder[*DeploymentRecord]{
+		"deployment":        decodeEventAs(newDeploymentRecordFromDeployment),
+		"deployment_status": decodeEventAs(newDeploymentRecordFromStatus),
+	},
+	rule_field_names: deploymentRuleFieldNames,
+	rule_fields:      deploymentRuleFields,
+	rows:             deploymentRows,
+}
+
+// NewGitHubDeploymentTransformation() creates a new `GitHubDeploymentTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubdeployment://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "deployment", "deployment_status" or "infer" to infer the event type from each message. Default is "deployment".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?environment` Zero or more `path.Match` patterns for the environments to process, for example "production" or "staging-*". Default is all environments.
+// * `?ref` Zero or more `path.Match` patterns for the refs (branches, tags or commit hashes) to process. Default is all refs.
+// * `?state` Zero or more deployment status states to process, for example "success" or "failure". `deployment` events have no state so they are never processed if this parameter is present. Default is all states.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubDeploymentTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "deployment", deploymentEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubDeploymentTransformation{
+		eventParams:  params,
+		environments: parseListParam(q, "environment"),
+		refs:         parseListParam(q, "ref"),
+		states:       parseListParam(q, "state"),
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `deployment` or `deployment_status` webhook message) in to CSV
+// data containing: the name of the repository, the environment, the ref, the commit hash, the state, the creator, the statuses URL
+// and the (JSON-encoded) deployment payload. If 'p' was created with `?format=json` or `?format=ndjson` the output will be a
+// JSON-encoded `DeploymentRecord`.
+func (p *GitHubDeploymentTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, deploymentEvents, p.filter, body)
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' does not match the environment, ref
+// and state filters used to create 'p'. Otherwise it returns nil.
+func (p *GitHubDeploymentTransformation) filter(rec *DeploymentRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	switch {
+	case !matchesPattern(rec.Environment, p.environments):
+		msg = fmt.Sprintf("Halt (environment %s)", rec.Environment)
+	case !matchesPattern(rec.Ref, p.refs):
+		msg = fmt.Sprintf("Halt (ref %s)", rec.Ref)
+	case !matchesPattern(rec.State, p.states):
+		msg = fmt.Sprintf("Halt (state %s)", rec.State)
+	default:
+		return nil
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
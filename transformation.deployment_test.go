@@ -0,0 +1,145 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubDeploymentTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri      string
+		msg      string
+		expected string
+	}{
+		{
+			"githubdeployment://",
+			"fixtures/events/deployment.json",
+			`sfomuseum-data-flights-2020-05,production,main,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,,sfomuseumbot,https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/deployments/882109417/statuses,"{""collection"":""flights"",""month"":""2020-05"",""reindex"":true}"` + "\n",
+		},
+		{
+			"githubdeployment://?event=deployment_status",
+			"fixtures/events/deployment_status.json",
+			`sfomuseum-data-flights-2020-05,production,main,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,success,sfomuseumbot,https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/deployments/882109417/statuses,"{""collection"":""flights"",""month"":""2020-05"",""reindex"":true}"` + "\n",
+		},
+		{
+			"githubdeployment://?event=infer",
+			"fixtures/events/deployment_status.json",
+			`sfomuseum-data-flights-2020-05,production,main,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,success,sfomuseumbot,https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/deployments/882109417/statuses,"{""collection"":""flights"",""month"":""2020-05"",""reindex"":true}"` + "\n",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		body := readFixture(t, test.msg)
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		if string(rsp) != test.expected {
+			t.Fatalf("Unexpected output for %s: '%s'", test.msg, string(rsp))
+		}
+	}
+}
+
+func TestGitHubDeploymentTransformationWithJSON(t *testing.T) {
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubdeployment://?event=deployment_status&format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, readFixture(t, "fixtures/events/deployment_status.json"))
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec DeploymentRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	if rec.Schema != DEPLOYMENT_SCHEMA || rec.ID != 882109417 || rec.Description != "Published 1,204 records" || rec.LogURL != "https://publish.sfomuseum.org/jobs/5521/log" {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+
+	var payload map[string]interface{}
+
+	err = json.Unmarshal(rec.Payload, &payload)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal payload, %v", err)
+	}
+
+	if payload["month"] != "2020-05" {
+		t.Fatalf("Unexpected payload: %v", payload)
+	}
+}
+
+func TestGitHubDeploymentTransformationWithFilters(t *testing.T) {
+
+	deployment := readFixture(t, "fixtures/events/deployment.json")
+	status := readFixture(t, "fixtures/events/deployment_status.json")
+	failure := bytes.Replace(status, []byte(`"state": "success"`), []byte(`"state": "failure"`), 1)
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubdeployment://?environment=production&ref=main", deployment, false},
+		{"githubdeployment://?environment=staging-*", deployment, true},
+		{"githubdeployment://?ref=v*", deployment, true},
+		{"githubdeployment://?state=success", deployment, true},
+		{"githubdeployment://?event=deployment_status&state=success,failure", failure, false},
+		{"githubdeployment://?event=deployment_status&state=success", failure, true},
+		{"githubdeployment://?event=deployment_status&only_if=creator%3D%3Dsfomuseumbot", status, false},
+		{"githubdeployment://?halt_if=task%21%3Ddeploy", deployment, false},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+}
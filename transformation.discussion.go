@@ -0,0 +1,122 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubdiscussion", NewGitHubDiscussionTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubDiscussionTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub `discussion`,
+// `discussion_comment` and `commit_comment` webhook messages in to discussion and comment metadata.
+type GitHubDiscussionTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of discussion category patterns to process. If empty all categories are processed.
+	categories []string
+	// The list of (upper-case) author associations to process. If empty all associations are processed.
+	associations []string
+}
+
+// discussionEvents defines how `discussion`, `discussion_comment` and `commit_comment` events are handled by the
+// `GitHubDiscussionTransformation`. go-github does not define a DiscussionCommentEvent type so `discussion_comment` events are
+// decoded as `DiscussionEvent` instances; see newDiscussionRecordFromComment for details.
+var discussionEvents = &eventHandler[*DiscussionRecord]{
+	decoders: map[string]eventDecoder[*DiscussionRecord]{
+		"discussion":         decodeEventAs(newDiscussionRecordFromDiscussion),
+		"discussion_comment": decodeEventWithBody(newDiscussionRecordFromComment),
+		"commit_comment":     decodeEventWithBody(newDiscussionRecordFromCommitComment),
+	},
+	rule_field_names: discussionRuleFieldNames,
+	rule_fields:      discussionRuleFields,
+	rows:             discussionRows,
+}
+
+// NewGitHubDiscussionTransformation() creates a new `GitHubDiscussionTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubdiscussion://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "discussion", "discussion_comment", "commit_comment" or "infer" to infer the event type from each message. Default is "discussion".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process, for example "created", "answered" or "category_changed". Default is all actions.
+// * `?category` Zero or more `path.Match` patterns for the names of the discussion categories to process, for example "Q&A". `commit_comment` events have no category so they are never processed if this parameter is present. Default is all categories.
+// * `?association` Zero or more author associations to process, for example "OWNER", "MEMBER" or "NONE". For comments this is the association of the author of the comment. Default is all associations.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubDiscussionTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "discussion", discussionEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubDiscussionTransformation{
+		eventParams:  params,
+		actions:      parseListParam(q, "action"),
+		categories:   parseListParam(q, "category"),
+		associations: parseListParam(q, "association"),
+	}
+
+	for idx, a := range p.associations {
+		p.associations[idx] = strings.ToUpper(a)
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `discussion`, `discussion_comment` or `commit_comment` webhook message)
+// in to CSV data containing: the name of the repository, the name of the event, the action, the discussion number, the category, the
+// author, the author's association with the repository, the commit hash, the path and line of the file commented on and the URL of the
+// discussion or comment. If 'p' was created with `?format=json` or `?format=ndjson` the output will be a JSON-encoded `DiscussionRecord`.
+func (p *GitHubDiscussionTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, discussionEvents, p.filter, body)
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' does not match the action, category
+// and association filters used to create 'p'. Otherwise it returns nil.
+func (p *GitHubDiscussionTransformation) filter(rec *DiscussionRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	switch {
+	case !matchesPattern(rec.Action, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case !matchesPattern(rec.Category, p.categories):
+		msg = fmt.Sprintf("Halt (category %s)", rec.Category)
+	case !matchesPattern(rec.Association, p.associations):
+		msg = fmt.Sprintf("Halt (association %s)", rec.Association)
+	default:
+		return nil
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
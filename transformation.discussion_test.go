@@ -0,0 +1,143 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubDiscussionTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri      string
+		msg      string
+		expected string
+	}{
+		{
+			"githubdiscussion://",
+			"fixtures/events/discussion.json",
+			"sfomuseum-data-flights-2020-05,discussion,created,17,Q&A,gazetteer-fan,NONE,,,,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/discussions/17\n",
+		},
+		{
+			"githubdiscussion://?event=infer",
+			"fixtures/events/discussion_comment.json",
+			"sfomuseum-data-flights-2020-05,discussion_comment,created,17,Q&A,thisisaaronland,MEMBER,,,,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/discussions/17#discussioncomment-6109822\n",
+		},
+		{
+			"githubdiscussion://?event=commit_comment",
+			"fixtures/events/commit_comment.json",
+			"sfomuseum-data-flights-2020-05,commit_comment,created,,,gazetteer-fan,CONTRIBUTOR,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,data/171/316/450/9/1713164509.geojson,17,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/commit/e3a18d4de60a5e50ca78ca1733238735ddfaef4c#r115832207\n",
+		},
+		{
+			"githubdiscussion://?event=infer&min_confidence=medium",
+			"fixtures/events/commit_comment.json",
+			"sfomuseum-data-flights-2020-05,commit_comment,created,,,gazetteer-fan,CONTRIBUTOR,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,data/171/316/450/9/1713164509.geojson,17,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/commit/e3a18d4de60a5e50ca78ca1733238735ddfaef4c#r115832207\n",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		body := readFixture(t, test.msg)
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		if string(rsp) != test.expected {
+			t.Fatalf("Unexpected output for %s: '%s'", test.msg, string(rsp))
+		}
+	}
+}
+
+func TestGitHubDiscussionTransformationWithJSON(t *testing.T) {
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubdiscussion://?event=discussion_comment&format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, readFixture(t, "fixtures/events/discussion_comment.json"))
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec DiscussionRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal record, %v", err)
+	}
+
+	if rec.Schema != DISCUSSION_SCHEMA || rec.Number != 17 || rec.CommentID != 6109822 || rec.Answered {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+
+	if rec.Body != "Not intentional, thanks. It will be fixed in the next import." {
+		t.Fatalf("Unexpected body: %s", rec.Body)
+	}
+}
+
+func TestGitHubDiscussionTransformationWithFilters(t *testing.T) {
+
+	discussion := readFixture(t, "fixtures/events/discussion.json")
+	ideas := bytes.Replace(discussion, []byte(`"name": "Q&A"`), []byte(`"name": "Ideas"`), 1)
+
+	comment := readFixture(t, "fixtures/events/commit_comment.json")
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubdiscussion://?category=Q%26A&action=created&association=none", discussion, false},
+		{"githubdiscussion://?category=Q%26A", ideas, true},
+		{"githubdiscussion://?action=answered", discussion, true},
+		{"githubdiscussion://?association=MEMBER,OWNER", discussion, true},
+		{"githubdiscussion://?event=commit_comment&association=contributor", comment, false},
+		{"githubdiscussion://?event=commit_comment&category=Q%26A", comment, true},
+		{"githubdiscussion://?event=commit_comment&only_if=path%3D~%5Edata/", comment, false},
+		{"githubdiscussion://?halt_if=title%3D~terminal", discussion, true},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+}
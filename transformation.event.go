@@ -0,0 +1,141 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubevent", NewGitHubEventTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubEventTransformation implements the `webhookd.WebhookTransformation` interface for transforming any supported
+// GitHub webhook message in to a normalized, JSON-encoded `EventRecord`.
+type GitHubEventTransformation struct {
+	webhookd.WebhookTransformation
+	// IncludePayload is a boolean flag to include the original webhook message in the final output.
+	IncludePayload bool
+	// The name of the GitHub event that webhook messages are expected to be, or `EVENT_INFER`.
+	event_type string
+	// The minimum confidence required for an inferred event type to be accepted, if 'event_type' is `EVENT_INFER`.
+	min_confidence Confidence
+	// The set of rules used to determine whether the transformer should return an error with code `webhookd.HaltEvent`.
+	rules *RuleSet
+}
+
+// NewGitHubEventTransformation() creates a new `GitHubEventTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubevent://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be, or "infer" to infer the event type from each message. Default is "push".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?include_payload` An optional boolean value to include the original webhook message in the final output.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+func NewGitHubEventTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	event_type, min_confidence, err := parseEventParams(q, "push")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if event_type != EVENT_INFER {
+
+		err := validateEventType(event_type)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?event= parameter, %w", err)
+		}
+	}
+
+	include_payload := false
+
+	err = parseBoolParams(q, map[string]*bool{
+		"include_payload": &include_payload,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := NewRuleSetFromQuery(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = rules.Validate(eventRuleFieldNames)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubEventTransformation{
+		IncludePayload: include_payload,
+		event_type:     event_type,
+		min_confidence: min_confidence,
+		rules:          rules,
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub webhook message of the event type used to create 'p')
+// in to a JSON-encoded `EventRecord`.
+func (p *GitHubEventTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+
+	select {
+	case <-ctx.Done():
+		return nil, nil
+	default:
+		// pass
+	}
+
+	event_type, err2 := resolveEventType(p.event_type, p.min_confidence, body)
+
+	if err2 != nil {
+		return nil, err2
+	}
+
+	event, err := UnmarshalEvent(event_type, body)
+
+	if err != nil {
+		err := &webhookd.WebhookError{Code: 999, Message: err.Error()}
+		return nil, err
+	}
+
+	rec := newEventRecord(event_type, event)
+
+	err2 = p.rules.Evaluate(eventRuleFields(rec))
+
+	if err2 != nil {
+		return nil, err2
+	}
+
+	if p.IncludePayload {
+		rec.Payload = body
+	}
+
+	return marshalJSON(rec)
+}
@@ -0,0 +1,198 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubEventTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri        string
+		msg        string
+		event      string
+		action     string
+		ref        string
+		actor      string
+		subject    string
+		number     int
+		created_at string
+	}{
+		{"githubevent://", "fixtures/events/flights.json", "push", "", "refs/heads/main", "thisisaaronland", SUBJECT_COMMIT, 0, "2020-05-22T16:09:30Z"},
+		{"githubevent://?event=pull_request", "fixtures/events/pull_request.json", "pull_request", "opened", "refs/heads/gates-0530", "thisisaaronland", SUBJECT_PULL_REQUEST, 42, "2020-05-30T17:04:11Z"},
+		{"githubevent://?event=infer", "fixtures/events/pull_request.json", "pull_request", "opened", "refs/heads/gates-0530", "thisisaaronland", SUBJECT_PULL_REQUEST, 42, "2020-05-30T17:04:11Z"},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		fh, err := os.Open(test.msg)
+
+		if err != nil {
+			t.Fatalf("Failed to open %s, %v", test.msg, err)
+		}
+
+		defer fh.Close()
+
+		body, err := io.ReadAll(fh)
+
+		if err != nil {
+			t.Fatalf("Failed to read %s, %v", test.msg, err)
+		}
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		var rec EventRecord
+
+		err = json.Unmarshal(rsp, &rec)
+
+		if err != nil {
+			t.Fatalf("Failed to unmarshal output for %s, %v", test.msg, err)
+		}
+
+		if rec.Schema != EVENT_SCHEMA || rec.Version != EVENT_SCHEMA_VERSION {
+			t.Fatalf("Unexpected schema for %s: %s (%d)", test.msg, rec.Schema, rec.Version)
+		}
+
+		if rec.Event != test.event || rec.Action != test.action {
+			t.Fatalf("Unexpected event for %s: %s (%s)", test.msg, rec.Event, rec.Action)
+		}
+
+		if rec.FullName != "sfomuseum-data/sfomuseum-data-flights-2020-05" {
+			t.Fatalf("Unexpected repository for %s: %s", test.msg, rec.FullName)
+		}
+
+		if rec.Ref != test.ref {
+			t.Fatalf("Unexpected ref for %s: %s", test.msg, rec.Ref)
+		}
+
+		if rec.Actor != test.actor {
+			t.Fatalf("Unexpected actor for %s: %s", test.msg, rec.Actor)
+		}
+
+		if rec.Subject == nil || rec.Subject.Type != test.subject || rec.Subject.Number != test.number {
+			t.Fatalf("Unexpected subject for %s: %v", test.msg, rec.Subject)
+		}
+
+		if rec.CreatedAt != test.created_at {
+			t.Fatalf("Unexpected created at for %s: %s", test.msg, rec.CreatedAt)
+		}
+
+		if rec.Payload != nil {
+			t.Fatalf("Unexpected payload for %s", test.msg)
+		}
+	}
+}
+
+func TestGitHubEventTransformationWithPayload(t *testing.T) {
+
+	msg := "fixtures/events/pull_request.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubevent://?event=pull_request&include_payload=true")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec EventRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	var payload map[string]interface{}
+
+	err = json.Unmarshal(rec.Payload, &payload)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal payload, %v", err)
+	}
+
+	if payload["number"] != float64(42) {
+		t.Fatalf("Unexpected payload number: %v", payload["number"])
+	}
+}
+
+func TestGitHubEventTransformationWithRules(t *testing.T) {
+
+	msg := "fixtures/events/pull_request.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubevent://?event=pull_request&only_if=action%3D%3Dclosed")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	_, err2 := tr.Transform(ctx, body)
+
+	if err2 == nil || err2.Code != webhookd.HaltEvent {
+		t.Fatalf("Expected halt event, got %v", err2)
+	}
+
+	_, err = transformation.NewTransformation(ctx, "githubevent://?event=not_an_event")
+
+	if err == nil {
+		t.Fatalf("Expected error for invalid event type")
+	}
+
+	_, err = transformation.NewTransformation(ctx, "githubevent://?halt_if=path%3D%3Dfoo")
+
+	if err == nil {
+		t.Fatalf("Expected error for unknown rule field")
+	}
+}
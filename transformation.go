@@ -0,0 +1,199 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+)
+
+// eventDecoder is a function which decodes a GitHub webhook message in to a record of type 'R'.
+type eventDecoder[R any] func(body []byte) (R, error)
+
+// decodeEventAs returns an `eventDecoder` which unmarshals a webhook message in to a new instance of 'T' (using `UnmarshalEventAs`)
+// and derives a record from it using 'new_record'.
+func decodeEventAs[T any, R any](new_record func(*T) R) eventDecoder[R] {
+
+	return func(body []byte) (R, error) {
+
+		event, err := UnmarshalEventAs[T](body)
+
+		if err != nil {
+			var rec R
+			return rec, err
+		}
+
+		return new_record(event), nil
+	}
+}
+
+// decodeEventWithBody returns an `eventDecoder` which unmarshals a webhook message in to a new instance of 'T' (using `UnmarshalEventAs`)
+// and derives a record from it, and the webhook message itself, using 'new_record'. This is used for events with properties which
+// are not (yet) defined by go-github.
+func decodeEventWithBody[T any, R any](new_record func(*T, []byte) (R, error)) eventDecoder[R] {
+
+	return func(body []byte) (R, error) {
+
+		event, err := UnmarshalEventAs[T](body)
+
+		if err != nil {
+			var rec R
+			return rec, err
+		}
+
+		return new_record(event, body)
+	}
+}
+
+// eventHandler defines the event-specific parts of a transformation which encodes each GitHub event it processes as a single record
+// of type 'R'. Transformations use `newEventParams` to parse the parameters common to all such transformations and `transformEvent`
+// to implement their `Transform` method.
+type eventHandler[R any] struct {
+	// decoders maps the names of the GitHub events the transformation supports to the functions used to decode them.
+	decoders map[string]eventDecoder[R]
+	// rule_field_names is the list of field names that rules may be evaluated against.
+	rule_field_names []string
+	// rule_fields returns the `RuleFields` for a record.
+	rule_fields func(R) RuleFields
+	// enriched_rule_field_names is the (optional) list of field names whose values are only known after a record has been
+	// enriched (see `transformEventWithEnrichment`). Rules evaluated against these fields are deferred until after enrichment.
+	enriched_rule_field_names []string
+	// rows returns the CSV rows for a record.
+	rows func(R) [][]string
+}
+
+// eventParams contains the parameters common to all transformations which use an `eventHandler`.
+type eventParams struct {
+	// The name of the GitHub event that webhook messages are expected to be, or `EVENT_INFER`.
+	event_type string
+	// The minimum confidence required for an inferred event type to be accepted, if 'event_type' is `EVENT_INFER`.
+	min_confidence Confidence
+	// The set of rules used to determine whether the transformer should return an error with code `webhookd.HaltEvent`.
+	rules *RuleSet
+	// The format of the final output. Valid options are `FORMAT_CSV`, `FORMAT_JSON` and `FORMAT_NDJSON`.
+	format string
+}
+
+// newEventParams returns a new `eventParams` instance derived from the `?event`, `?min_confidence`, `?halt_if`, `?only_if` and
+// `?format` parameters in 'q'. If `?event=` is empty then 'default_event' is used. The event must be `EVENT_INFER` or one of the
+// events supported by 'h' and rules must only be evaluated against the fields defined by 'h'.
+func newEventParams[R any](q url.Values, default_event string, h *eventHandler[R]) (*eventParams, error) {
+
+	event_type, min_confidence, err := parseEventParams(q, default_event)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, ok := h.decoders[event_type]
+
+	if !ok && event_type != EVENT_INFER {
+		return nil, fmt.Errorf("Invalid ?event= parameter '%s'", event_type)
+	}
+
+	format, err := parseFormat(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := NewRuleSetFromQuery(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = rules.Validate(h.rule_field_names)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid rules, %w", err)
+	}
+
+	p := &eventParams{
+		event_type:     event_type,
+		min_confidence: min_confidence,
+		rules:          rules,
+		format:         format,
+	}
+
+	return p, nil
+}
+
+// transformEvent transforms 'body' in to a record using 'p' and 'h'. The event type of 'body' is resolved (or inferred) and decoded
+// using the matching decoder in 'h'. The record is then passed to 'filter', which may modify the record or return an error with code
+// `webhookd.HaltEvent`, and evaluated against the rules in 'p' before being encoded as JSON or as CSV rows.
+func transformEvent[R any](ctx context.Context, p *eventParams, h *eventHandler[R], filter func(R) *webhookd.WebhookError, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEventWithEnrichment(ctx, p, h, filter, nil, body)
+}
+
+// transformEventWithEnrichment is the same as `transformEvent` except that, if 'enrich' is not nil, records which are not halted by
+// 'filter' or by rules which do not depend on the fields in 'h.enriched_rule_field_names' are passed to 'enrich', for example to
+// retrieve additional data from the GitHub API, before the remaining rules are evaluated.
+func transformEventWithEnrichment[R any](ctx context.Context, p *eventParams, h *eventHandler[R], filter func(R) *webhookd.WebhookError, enrich func(context.Context, R) *webhookd.WebhookError, body []byte) ([]byte, *webhookd.WebhookError) {
+
+	select {
+	case <-ctx.Done():
+		return nil, nil
+	default:
+		// pass
+	}
+
+	event_type, err2 := resolveEventType(p.event_type, p.min_confidence, body)
+
+	if err2 != nil {
+		return nil, err2
+	}
+
+	decode, ok := h.decoders[event_type]
+
+	if !ok {
+		msg := fmt.Sprintf("Unsupported event type '%s'", event_type)
+		return nil, &webhookd.WebhookError{Code: webhookd.UnhandledEvent, Message: msg}
+	}
+
+	rec, err := decode(body)
+
+	if err != nil {
+		err := &webhookd.WebhookError{Code: 999, Message: err.Error()}
+		return nil, err
+	}
+
+	halt_err := filter(rec)
+
+	if halt_err != nil {
+		return nil, halt_err
+	}
+
+	// Rules that don't depend on enriched fields are evaluated before (possibly) enriching the record
+
+	enriched_rules, rules := p.rules.Partition(h.enriched_rule_field_names)
+
+	halt_err = rules.Evaluate(h.rule_fields(rec))
+
+	if halt_err != nil {
+		return nil, halt_err
+	}
+
+	if enrich != nil {
+
+		halt_err = enrich(ctx, rec)
+
+		if halt_err != nil {
+			return nil, halt_err
+		}
+	}
+
+	halt_err = enriched_rules.Evaluate(h.rule_fields(rec))
+
+	if halt_err != nil {
+		return nil, halt_err
+	}
+
+	switch p.format {
+	case FORMAT_JSON, FORMAT_NDJSON:
+		return marshalJSON(rec)
+	default:
+		return marshalCSV(h.rows(rec))
+	}
+}
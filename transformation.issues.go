@@ -0,0 +1,193 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubissues", NewGitHubIssuesTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubIssuesTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub `issues` and
+// `issue_comment` webhook messages in to issue metadata and the slash-commands contained in the body of the issue or comment.
+type GitHubIssuesTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// ExcludePullRequests is a boolean flag to halt processing of events for issues which are pull requests.
+	ExcludePullRequests bool
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of labels, at least one of which must be assigned to an issue for it to be processed.
+	labels []string
+	// The list of labels, none of which may be assigned to an issue for it to be processed.
+	exclude_labels []string
+	// The list of (upper-case) author associations to process.
+	associations []string
+	// The list of issue states to process. If empty all states are processed.
+	states []string
+	// The list of (lower-case) commands to process. If empty all commands, and events without commands, are processed.
+	commands []string
+	// The list of actions for which slash-commands are parsed.
+	command_actions []string
+}
+
+// issueEvents defines how `issues` and `issue_comment` events are handled by the `GitHubIssuesTransformation`.
+var issueEvents = &eventHandler[*IssueRecord]{
+	decoders: map[string]eventDecoder[*IssueRecord]{
+		"issues":        decodeEventAs(newIssueRecordFromIssue),
+		"issue_comment": decodeEventAs(newIssueRecordFromComment),
+	},
+	rule_field_names: issueRuleFieldNames,
+	rule_fields:      issueRuleFields,
+	rows:             issueRows,
+}
+
+// NewGitHubIssuesTransformation() creates a new `GitHubIssuesTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubissues://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "issues", "issue_comment" or "infer" to infer the event type from each message. Default is "issues".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process, for example "opened", "labeled" or "created". Default is all actions.
+// * `?label` Zero or more labels, at least one of which must be assigned to an issue for it to be processed.
+// * `?exclude_label` Zero or more labels, none of which may be assigned to an issue for it to be processed.
+// * `?association` Zero or more `path.Match` patterns for the author associations to process, for example "OWNER", "MEMBER" or "COLLABORATOR". For `issue_comment` events this is the association of the author of the comment. Default is "OWNER", "MEMBER" and "COLLABORATOR"; use "*" to process all associations.
+// * `?state` Zero or more issue states ("open" or "closed") to process. Default is all states.
+// * `?command` Zero or more slash-commands (without the leading slash), at least one of which must be present in the body of the issue or comment for it to be processed. Other commands are removed from the output. Default is all commands.
+// * `?command_action` Zero or more actions for which slash-commands are parsed. Commands in issues or comments with other actions (for example "edited") are ignored. Default is "opened" and "created"; use "*" to parse commands for all actions.
+// * `?exclude_pull_requests` An optional boolean value to skip issues which are pull requests.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubIssuesTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "issues", issueEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubIssuesTransformation{
+		eventParams:    params,
+		actions:        parseListParam(q, "action"),
+		labels:         parseListParam(q, "label"),
+		exclude_labels: parseListParam(q, "exclude_label"),
+		associations:   parseListParam(q, "association"),
+		states:         parseListParam(q, "state"),
+		commands:       parseListParam(q, "command"),
+	}
+
+	if !q.Has("association") {
+		p.associations = append([]string(nil), issueDefaultAssociations...)
+	}
+
+	if q.Has("command_action") {
+		p.command_actions = parseListParam(q, "command_action")
+	} else {
+		p.command_actions = append([]string(nil), issueDefaultCommandActions...)
+	}
+
+	for idx, a := range p.associations {
+		p.associations[idx] = strings.ToUpper(a)
+	}
+
+	for idx, c := range p.commands {
+		p.commands[idx] = strings.ToLower(strings.TrimPrefix(c, "/"))
+	}
+
+	flags := map[string]*bool{
+		"exclude_pull_requests": &p.ExcludePullRequests,
+	}
+
+	err = parseBoolParams(q, flags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `issues` or `issue_comment` webhook message) in to CSV data
+// containing: the name of the repository, the issue number, the action, the author, the name of a slash-command and its
+// (space-separated) arguments. There is one row for each slash-command; events without slash-commands produce a single row
+// with empty command columns. If 'p' was created with `?format=json` or `?format=ndjson` the output will be a JSON-encoded `IssueRecord`.
+func (p *GitHubIssuesTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, issueEvents, p.filter, body)
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' does not match the action, label,
+// association, state and pull request filters used to create 'p'. Otherwise it removes the commands in 'rec' which do not
+// match the command and command action filters used to create 'p' and returns a `webhookd.WebhookError` with code
+// `webhookd.HaltEvent` if commands are required and none remain, or nil.
+func (p *GitHubIssuesTransformation) filter(rec *IssueRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	switch {
+	case !matchesPattern(rec.Action, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case len(p.labels) > 0 && !hasAnyLabel(rec.Labels, p.labels):
+		msg = "Halt (missing label)"
+	case hasAnyLabel(rec.Labels, p.exclude_labels):
+		msg = "Halt (excluded label)"
+	case !matchesPattern(rec.Association, p.associations):
+		msg = fmt.Sprintf("Halt (association %s)", rec.Association)
+	case !matchesPattern(rec.State, p.states):
+		msg = fmt.Sprintf("Halt (state %s)", rec.State)
+	case p.ExcludePullRequests && rec.PullRequest:
+		msg = "Halt (pull request)"
+	default:
+
+		if !matchesPattern(rec.Action, p.command_actions) {
+			rec.Commands = make([]*IssueCommand, 0)
+		}
+
+		if len(p.commands) == 0 {
+			return nil
+		}
+
+		commands := make([]*IssueCommand, 0)
+
+		for _, c := range rec.Commands {
+
+			if matchesPattern(c.Name, p.commands) {
+				commands = append(commands, c)
+			}
+		}
+
+		rec.Commands = commands
+
+		if len(commands) > 0 {
+			return nil
+		}
+
+		msg = "Halt (missing command)"
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
@@ -0,0 +1,173 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubIssuesTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri      string
+		msg      string
+		expected string
+	}{
+		{
+			"githubissues://?association=contributor",
+			"fixtures/events/issues.json",
+			"sfomuseum-data-flights-2020-05,87,opened,flightwatcher,,\n",
+		},
+		{
+			"githubissues://?event=issue_comment",
+			"fixtures/events/issue_comment.json",
+			"sfomuseum-data-flights-2020-05,87,created,thisisaaronland,reindex,1713164509\nsfomuseum-data-flights-2020-05,87,created,thisisaaronland,label,verified\n",
+		},
+		{
+			"githubissues://?event=infer&command=/reindex",
+			"fixtures/events/issue_comment.json",
+			"sfomuseum-data-flights-2020-05,87,created,thisisaaronland,reindex,1713164509\n",
+		},
+		{
+			"githubissues://?event=infer&min_confidence=medium&association=*",
+			"fixtures/events/issues.json",
+			"sfomuseum-data-flights-2020-05,87,opened,flightwatcher,,\n",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		body := readFixture(t, test.msg)
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		if string(rsp) != test.expected {
+			t.Fatalf("Unexpected output for %s: '%s'", test.msg, string(rsp))
+		}
+	}
+}
+
+func TestGitHubIssuesTransformationWithJSON(t *testing.T) {
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubissues://?event=issue_comment&format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, readFixture(t, "fixtures/events/issue_comment.json"))
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec IssueRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	if rec.Schema != ISSUE_SCHEMA || rec.Number != 87 || rec.Association != "MEMBER" || rec.CommentID != 1573802291 || rec.PullRequest {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+
+	if len(rec.Commands) != 2 || rec.Commands[0].Name != "reindex" || rec.Commands[0].Args[0] != "1713164509" || rec.Commands[0].Line != 3 {
+		t.Fatalf("Unexpected commands: %v", rec.Commands)
+	}
+}
+
+func TestGitHubIssuesTransformationWithFilters(t *testing.T) {
+
+	issue := readFixture(t, "fixtures/events/issues.json")
+	comment := readFixture(t, "fixtures/events/issue_comment.json")
+	closed := bytes.Replace(issue, []byte(`"state": "open"`), []byte(`"state": "closed"`), 1)
+	edited := bytes.Replace(comment, []byte(`"action": "created"`), []byte(`"action": "edited"`), 1)
+	pull_request := bytes.Replace(comment, []byte(`"timeline_url"`), []byte(`"pull_request": { "url": "https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/pulls/87" }, "timeline_url"`), 1)
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubissues://", issue, true},
+		{"githubissues://?association=*", issue, false},
+		{"githubissues://?action=opened&label=data-correction&state=open&association=contributor", issue, false},
+		{"githubissues://?action=closed", issue, true},
+		{"githubissues://?label=bug", issue, true},
+		{"githubissues://?exclude_label=data-correction", issue, true},
+		{"githubissues://?state=open", closed, true},
+		{"githubissues://?association=owner,member", issue, true},
+		{"githubissues://?command=reindex", issue, true},
+		{"githubissues://?event=issue_comment&association=member&command=reindex,close", comment, false},
+		{"githubissues://?event=issue_comment&command=close", comment, true},
+		{"githubissues://?event=issue_comment&command=reindex", edited, true},
+		{"githubissues://?event=issue_comment&command=reindex&command_action=created,edited", edited, false},
+		{"githubissues://?event=issue_comment&exclude_pull_requests=true", comment, false},
+		{"githubissues://?event=issue_comment&exclude_pull_requests=true", pull_request, true},
+		{"githubissues://?event=issue_comment&only_if=command%3D%3Dreindex", comment, false},
+		{"githubissues://?event=issue_comment&halt_if=author%3D%3Dthisisaaronland", comment, true},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+}
+
+func TestGitHubIssuesTransformationDefaultsAreCopied(t *testing.T) {
+
+	ctx := context.Background()
+
+	tr, err := NewGitHubIssuesTransformation(ctx, "githubissues://")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	p := tr.(*GitHubIssuesTransformation)
+
+	p.associations[0] = "NONE"
+	p.command_actions[0] = "edited"
+
+	if issueDefaultAssociations[0] != "OWNER" || issueDefaultCommandActions[0] != "opened" {
+		t.Fatalf("Transformation modified default associations or command actions")
+	}
+}
@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubrepolifecycle", NewGitHubRepoLifecycleTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubRepoLifecycleTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub `repository`,
+// `public`, `fork` and `member` webhook messages in to records describing changes to the name, owner, visibility, archived state
+// and collaborators of a repository.
+type GitHubRepoLifecycleTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of repository name patterns to process. If empty all repositories are processed.
+	repos []string
+}
+
+// lifecycleEvents defines how `repository`, `public`, `fork` and `member` events are handled by the `GitHubRepoLifecycleTransformation`.
+var lifecycleEvents = &eventHandler[*LifecycleRecord]{
+	decoders: map[string]eventDecoder[*LifecycleRecord]{
+		"repository": decodeEventWithBody(newLifecycleRecordFromRepository),
+		"public":     decodeEventAs(newLifecycleRecordFromPublic),
+		"fork":       decodeEventAs(newLifecycleRecordFromFork),
+		"member":     decodeEventWithBody(newLifecycleRecordFromMember),
+	},
+	rule_field_names: lifecycleRuleFieldNames,
+	rule_fields:      lifecycleRuleFields,
+	rows:             lifecycleRows,
+}
+
+// NewGitHubRepoLifecycleTransformation() creates a new `GitHubRepoLifecycleTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubrepolifecycle://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "repository", "public", "fork", "member" or "infer" to infer the event type from each message. `repository` and `public` events can not be inferred. Default is "repository".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process, for example "renamed", "archived", "transferred", "publicized", "forked" or "added". Default is all actions.
+// * `?repo` Zero or more `path.Match` patterns for the (current or previous) names of the repositories to process, for example "sfomuseum-data-*". Default is all repositories.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubRepoLifecycleTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "repository", lifecycleEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubRepoLifecycleTransformation{
+		eventParams: params,
+		actions:     parseListParam(q, "action"),
+		repos:       parseListParam(q, "repo"),
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `repository`, `public`, `fork` or `member` webhook message) in to
+// CSV data containing: the name of the repository, the action, the previous name of the repository, the owner, the previous owner,
+// the visibility and the archived state. If 'p' was created with `?format=json` or `?format=ndjson` the output will be a JSON-encoded
+// `LifecycleRecord`.
+func (p *GitHubRepoLifecycleTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, lifecycleEvents, p.filter, body)
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' does not match the action and repository
+// filters used to create 'p'. Otherwise it returns nil.
+func (p *GitHubRepoLifecycleTransformation) filter(rec *LifecycleRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	switch {
+	case !matchesPattern(rec.Action, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case !matchesPattern(rec.Repo, p.repos) && (rec.PreviousName == "" || !matchesPattern(rec.PreviousName, p.repos)):
+		msg = fmt.Sprintf("Halt (repo %s)", rec.Repo)
+	default:
+		return nil
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
@@ -0,0 +1,179 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubRepoLifecycleTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri      string
+		msg      string
+		expected string
+	}{
+		{
+			"githubrepolifecycle://",
+			"fixtures/events/repository_renamed.json",
+			"sfomuseum-data-flights-2020-05,renamed,sfomuseum-data-flights-2020-5,sfomuseum-data,,public,false\n",
+		},
+		{
+			"githubrepolifecycle://",
+			"fixtures/events/repository_transferred.json",
+			"sfomuseum-data-flights-2020-05,transferred,,sfomuseum-data,thisisaaronland,public,false\n",
+		},
+		{
+			"githubrepolifecycle://?event=public",
+			"fixtures/events/public.json",
+			"sfomuseum-data-flights-2020-05,publicized,,sfomuseum-data,,public,false\n",
+		},
+		{
+			"githubrepolifecycle://?event=infer",
+			"fixtures/events/fork.json",
+			"sfomuseum-data-flights-2020-05,forked,,sfomuseum-data,,public,false\n",
+		},
+		{
+			"githubrepolifecycle://?event=member",
+			"fixtures/events/member.json",
+			"sfomuseum-data-flights-2020-05,edited,,sfomuseum-data,,public,false\n",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		body := readFixture(t, test.msg)
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		if string(rsp) != test.expected {
+			t.Fatalf("Unexpected output for %s: '%s'", test.msg, string(rsp))
+		}
+	}
+}
+
+func TestGitHubRepoLifecycleTransformationWithJSON(t *testing.T) {
+
+	ctx := context.Background()
+
+	tests := []struct {
+		uri   string
+		msg   string
+		check func(rec *LifecycleRecord) bool
+	}{
+		{
+			"githubrepolifecycle://?format=json",
+			"fixtures/events/repository_renamed.json",
+			func(rec *LifecycleRecord) bool {
+				return rec.Schema == LIFECYCLE_SCHEMA && rec.PreviousName == "sfomuseum-data-flights-2020-5" && rec.Actor == "thisisaaronland"
+			},
+		},
+		{
+			"githubrepolifecycle://?event=public&format=json",
+			"fixtures/events/public.json",
+			func(rec *LifecycleRecord) bool {
+				return rec.Visibility == "public" && rec.PreviousVisibility == "private"
+			},
+		},
+		{
+			"githubrepolifecycle://?event=fork&format=json",
+			"fixtures/events/fork.json",
+			func(rec *LifecycleRecord) bool {
+				return rec.Fork == "flightwatcher/sfomuseum-data-flights-2020-05" && rec.Actor == "flightwatcher"
+			},
+		},
+		{
+			"githubrepolifecycle://?event=member&format=json",
+			"fixtures/events/member.json",
+			func(rec *LifecycleRecord) bool {
+				return rec.Member == "flightwatcher" && rec.Permission == "write" && rec.PreviousPermission == "read"
+			},
+		},
+	}
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, readFixture(t, test.msg))
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		var rec LifecycleRecord
+
+		err = json.Unmarshal(rsp, &rec)
+
+		if err != nil {
+			t.Fatalf("Failed to unmarshal output for %s, %v", test.msg, err)
+		}
+
+		if !test.check(&rec) {
+			t.Fatalf("Unexpected record for %s: %v", test.msg, rec)
+		}
+	}
+}
+
+func TestGitHubRepoLifecycleTransformationWithFilters(t *testing.T) {
+
+	renamed := readFixture(t, "fixtures/events/repository_renamed.json")
+	archived := bytes.Replace(renamed, []byte(`"action": "renamed"`), []byte(`"action": "archived"`), 1)
+	archived = bytes.Replace(archived, []byte(`"archived": false`), []byte(`"archived": true`), 1)
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubrepolifecycle://?action=renamed,transferred&repo=sfomuseum-data-*", renamed, false},
+		{"githubrepolifecycle://?action=archived", renamed, true},
+		{"githubrepolifecycle://?action=archived&only_if=archived%3D%3Dtrue", archived, false},
+		{"githubrepolifecycle://?repo=whosonfirst-data-*", renamed, true},
+		{"githubrepolifecycle://?repo=sfomuseum-data-flights-2020-5", renamed, false},
+		{"githubrepolifecycle://?halt_if=actor%3D%3Dthisisaaronland", renamed, true},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+}
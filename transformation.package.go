@@ -0,0 +1,120 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubpackage", NewGitHubPackageTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubPackageTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub `package`
+// and `registry_package` webhook messages in to package version metadata.
+type GitHubPackageTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of (lower-case) package types to process. If empty all package types are processed.
+	package_types []string
+	// The list of package name patterns to process. If empty all packages are processed.
+	names []string
+}
+
+// packageEvents defines how `package` and `registry_package` events are handled by the `GitHubPackageTransformation`. go-github
+// does not define a RegistryPackageEvent type so both events are decoded as `packageEvent` instances; see packagePayload for details.
+var packageEvents = &eventHandler[*PackageRecord]{
+	decoders: map[string]eventDecoder[*PackageRecord]{
+		"package":          decodeEventAs(newPackageRecordFromPackage),
+		"registry_package": decodeEventAs(newPackageRecordFromRegistryPackage),
+	},
+	rule_field_names: packageRuleFieldNames,
+	rule_fields:      packageRuleFields,
+	rows:             packageRows,
+}
+
+// NewGitHubPackageTransformation() creates a new `GitHubPackageTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubpackage://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "package", "registry_package" or "infer" to infer the event type from each message. Default is "package".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process: "published" or "updated". Default is all actions.
+// * `?package_type` Zero or more package types to process, for example "container", "npm" or "maven". Default is all package types.
+// * `?name` Zero or more `path.Match` patterns for the names of the packages to process, for example "flights-*". Default is all packages.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubPackageTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "package", packageEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubPackageTransformation{
+		eventParams:   params,
+		actions:       parseListParam(q, "action"),
+		package_types: parseListParam(q, "package_type"),
+		names:         parseListParam(q, "name"),
+	}
+
+	for idx, t := range p.package_types {
+		p.package_types[idx] = strings.ToLower(t)
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `package` or `registry_package` webhook message) in to CSV data
+// containing: the name of the repository, the name of the event, the action, the package type, the package name, the package
+// version, the (space-separated) list of tags and the fully qualified package reference. If 'p' was created with `?format=json`
+// or `?format=ndjson` the output will be a JSON-encoded `PackageRecord`.
+func (p *GitHubPackageTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, packageEvents, p.filter, body)
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' does not match the action, package type
+// and name filters used to create 'p'. Otherwise it returns nil.
+func (p *GitHubPackageTransformation) filter(rec *PackageRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	switch {
+	case !matchesPattern(rec.Action, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case !matchesPattern(rec.PackageType, p.package_types):
+		msg = fmt.Sprintf("Halt (package type %s)", rec.PackageType)
+	case !matchesPattern(rec.Name, p.names):
+		msg = fmt.Sprintf("Halt (name %s)", rec.Name)
+	default:
+		return nil
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
@@ -0,0 +1,138 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubPackageTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri      string
+		msg      string
+		expected string
+	}{
+		{
+			"githubpackage://",
+			"fixtures/events/package.json",
+			"sfomuseum-data-flights-2020-05,package,published,npm,flights-2020-05,1.4.0,,@sfomuseum-data/flights-2020-05@1.4.0\n",
+		},
+		{
+			"githubpackage://?event=registry_package",
+			"fixtures/events/registry_package.json",
+			"sfomuseum-data-flights-2020-05,registry_package,published,container,flights-2020-05,sha256:9b1f3c5d7e2a4b6c8d0e1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e,2020-05 latest,ghcr.io/sfomuseum-data/flights-2020-05@sha256:9b1f3c5d7e2a4b6c8d0e1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e\n",
+		},
+		{
+			"githubpackage://?event=infer",
+			"fixtures/events/registry_package.json",
+			"sfomuseum-data-flights-2020-05,registry_package,published,container,flights-2020-05,sha256:9b1f3c5d7e2a4b6c8d0e1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e,2020-05 latest,ghcr.io/sfomuseum-data/flights-2020-05@sha256:9b1f3c5d7e2a4b6c8d0e1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e\n",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		body := readFixture(t, test.msg)
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		if string(rsp) != test.expected {
+			t.Fatalf("Unexpected output for %s: '%s'", test.msg, string(rsp))
+		}
+	}
+}
+
+func TestGitHubPackageTransformationWithJSON(t *testing.T) {
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubpackage://?event=registry_package&format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, readFixture(t, "fixtures/events/registry_package.json"))
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec PackageRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal record, %v", err)
+	}
+
+	if rec.Schema != PACKAGE_SCHEMA || rec.Owner != "sfomuseum-data" || rec.Publisher != "sfomuseumbot" || len(rec.Tags) != 2 {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+
+	if rec.SHA != "e3a18d4de60a5e50ca78ca1733238735ddfaef4c" {
+		t.Fatalf("Unexpected SHA: %s", rec.SHA)
+	}
+}
+
+func TestGitHubPackageTransformationWithFilters(t *testing.T) {
+
+	npm := readFixture(t, "fixtures/events/package.json")
+	updated := bytes.Replace(npm, []byte(`"action": "published"`), []byte(`"action": "updated"`), 1)
+
+	container := readFixture(t, "fixtures/events/registry_package.json")
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubpackage://?action=published&package_type=npm&name=flights-*", npm, false},
+		{"githubpackage://?action=published", updated, true},
+		{"githubpackage://?package_type=container,docker", npm, true},
+		{"githubpackage://?event=registry_package&package_type=CONTAINER", container, false},
+		{"githubpackage://?name=gazetteer-*", npm, true},
+		{"githubpackage://?event=registry_package&only_if=tag%3D%3Dlatest", container, false},
+		{"githubpackage://?halt_if=tag%3D%3Dlatest", npm, false},
+		{"githubpackage://?event=registry_package&halt_if=tag%3D%3Dlatest", container, true},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+}
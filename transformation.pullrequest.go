@@ -0,0 +1,187 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	gogithub "github.com/google/go-github/v48/github"
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubpullrequest", NewGitHubPullRequestTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubPullRequestTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub
+// `pull_request` webhook messages in to pull request metadata and, optionally, the list of files changed by the pull request.
+type GitHubPullRequestTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// ExcludeDrafts is a boolean flag to halt processing of events for draft pull requests.
+	ExcludeDrafts bool
+	// IncludeFiles is a boolean flag to retrieve the list of files changed by the pull request from the GitHub API.
+	IncludeFiles bool
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of base branch patterns to process. If empty all base branches are processed.
+	base []string
+	// The list of head branch patterns to process. If empty all head branches are processed.
+	head []string
+	// The list of labels, at least one of which must be assigned to a pull request for it to be processed.
+	labels []string
+	// The list of labels, none of which may be assigned to a pull request for it to be processed.
+	exclude_labels []string
+	// An optional GitHub API client used to retrieve the list of changed files.
+	client *gogithub.Client
+}
+
+// pullRequestEvents defines how `pull_request` events are handled by the `GitHubPullRequestTransformation`.
+var pullRequestEvents = &eventHandler[*PullRequestRecord]{
+	decoders: map[string]eventDecoder[*PullRequestRecord]{
+		"pull_request": decodeEventAs(newPullRequestRecord),
+	},
+	rule_field_names:          pullRequestRuleFieldNames,
+	rule_fields:               pullRequestRuleFields,
+	enriched_rule_field_names: pullRequestFileRuleFieldNames,
+	rows:                      pullRequestRows,
+}
+
+// NewGitHubPullRequestTransformation() creates a new `GitHubPullRequestTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubpullrequest://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "pull_request" or "infer" to infer the event type from each message. Default is "pull_request".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process, for example "opened", "synchronize", "labeled" or "merged" (a "closed" event whose pull request was merged). Default is all actions.
+// * `?base` Zero or more `path.Match` patterns for the base branch of pull requests to process. Default is all branches.
+// * `?head` Zero or more `path.Match` patterns for the head branch of pull requests to process. Default is all branches.
+// * `?label` Zero or more labels, at least one of which must be assigned to a pull request for it to be processed.
+// * `?exclude_label` Zero or more labels, none of which may be assigned to a pull request for it to be processed.
+// * `?exclude_drafts` An optional boolean value to skip draft pull requests.
+// * `?include_files` An optional boolean value to retrieve the list of files changed by the pull request from the GitHub API.
+// * `?api_token` An optional GitHub API access token to use when retrieving the list of changed files.
+// * `?api_base_url` An optional base URL for the GitHub API. Default is `DEFAULT_API_BASE_URL`.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`. Rules which are not evaluated against the "path" field are evaluated before the list of changed files is retrieved.
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Pull requests which are not processed cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubPullRequestTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "pull_request", pullRequestEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubPullRequestTransformation{
+		eventParams:    params,
+		actions:        parseListParam(q, "action"),
+		base:           parseListParam(q, "base"),
+		head:           parseListParam(q, "head"),
+		labels:         parseListParam(q, "label"),
+		exclude_labels: parseListParam(q, "exclude_label"),
+	}
+
+	flags := map[string]*bool{
+		"exclude_drafts": &p.ExcludeDrafts,
+		"include_files":  &p.IncludeFiles,
+	}
+
+	err = parseBoolParams(q, flags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.IncludeFiles {
+
+		client, err := newAPIClient(q.Get("api_base_url"), q.Get("api_token"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create API client, %w", err)
+		}
+
+		p.client = client
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `pull_request` webhook message) in to CSV data containing:
+// the name of the repository, the pull request number, the action, the base branch, the head branch and the head commit hash.
+// If 'p' was created with `?include_files=true` the output will instead contain one row for each file changed by the pull
+// request containing: the name of the repository, the pull request number, the head commit hash, the status of the file and
+// its path. If 'p' was created with `?format=json` or `?format=ndjson` the output will be a JSON-encoded `PullRequestRecord`.
+func (p *GitHubPullRequestTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEventWithEnrichment(ctx, p.eventParams, pullRequestEvents, p.filter, p.includeFiles, body)
+}
+
+// includeFiles retrieves the list of files changed by the pull request in 'rec' from the GitHub API, if 'p' was created with
+// `?include_files=true`. It returns a `webhookd.WebhookError` if the list can not be retrieved. Otherwise it returns nil.
+func (p *GitHubPullRequestTransformation) includeFiles(ctx context.Context, rec *PullRequestRecord) *webhookd.WebhookError {
+
+	if p.client == nil {
+		return nil
+	}
+
+	owner, repo, err := splitFullName(rec.FullName)
+
+	if err != nil {
+		return &webhookd.WebhookError{Code: 999, Message: err.Error()}
+	}
+
+	files, err := listPullRequestFiles(ctx, p.client, owner, repo, rec.Number)
+
+	if err != nil {
+		return &webhookd.WebhookError{Code: http.StatusBadGateway, Message: err.Error()}
+	}
+
+	rec.Files = files
+	return nil
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' does not match the action, branch,
+// draft and label filters used to create 'p'. Otherwise it returns nil.
+func (p *GitHubPullRequestTransformation) filter(rec *PullRequestRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	switch {
+	case !matchesAction(rec, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case !matchesPattern(rec.Base, p.base):
+		msg = fmt.Sprintf("Halt (base %s)", rec.Base)
+	case !matchesPattern(rec.Head, p.head):
+		msg = fmt.Sprintf("Halt (head %s)", rec.Head)
+	case p.ExcludeDrafts && rec.Draft:
+		msg = "Halt (draft)"
+	case len(p.labels) > 0 && !hasAnyLabel(rec.Labels, p.labels):
+		msg = "Halt (missing label)"
+	case hasAnyLabel(rec.Labels, p.exclude_labels):
+		msg = "Halt (excluded label)"
+	default:
+		return nil
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
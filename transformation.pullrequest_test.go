@@ -0,0 +1,321 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubPullRequestTransformation(t *testing.T) {
+
+	expected := []byte("sfomuseum-data-flights-2020-05,42,opened,main,gates-0530,7d3c0a1b9e8f6a5d4c3b2a1f0e9d8c7b6a5f4e3d\n")
+
+	body := readFixture(t, "fixtures/events/pull_request.json")
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubpullrequest://")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	if !bytes.Equal(rsp, expected) {
+		t.Fatalf("Unexpected output: '%s'", string(rsp))
+	}
+}
+
+func TestGitHubPullRequestTransformationWithJSON(t *testing.T) {
+
+	body := readFixture(t, "fixtures/events/pull_request_closed.json")
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubpullrequest://?format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec PullRequestRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	if rec.Schema != PULL_REQUEST_SCHEMA || rec.Number != 42 || rec.Action != "closed" {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+
+	if !rec.Merged || rec.MergeCommit != "b6f1e2d3c4a5968778695a4b3c2d1e0f9a8b7c6d" {
+		t.Fatalf("Unexpected merge state: %t %s", rec.Merged, rec.MergeCommit)
+	}
+
+	if len(rec.Labels) != 1 || rec.Labels[0] != "data" {
+		t.Fatalf("Unexpected labels: %v", rec.Labels)
+	}
+
+	if rec.Files != nil {
+		t.Fatalf("Unexpected files: %v", rec.Files)
+	}
+}
+
+func TestGitHubPullRequestTransformationWithFilters(t *testing.T) {
+
+	opened := readFixture(t, "fixtures/events/pull_request.json")
+	closed := readFixture(t, "fixtures/events/pull_request_closed.json")
+	draft := bytes.Replace(opened, []byte(`"draft": false`), []byte(`"draft": true`), 1)
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubpullrequest://?action=opened,synchronize", opened, false},
+		{"githubpullrequest://?action=merged", opened, true},
+		{"githubpullrequest://?action=merged", closed, false},
+		{"githubpullrequest://?action=closed", closed, false},
+		{"githubpullrequest://?base=main", opened, false},
+		{"githubpullrequest://?base=release/*", opened, true},
+		{"githubpullrequest://?head=gates-*", opened, false},
+		{"githubpullrequest://?head=feature/*", opened, true},
+		{"githubpullrequest://?exclude_drafts=true", opened, false},
+		{"githubpullrequest://?exclude_drafts=true", draft, true},
+		{"githubpullrequest://?label=flights", opened, false},
+		{"githubpullrequest://?label=flights", closed, true},
+		{"githubpullrequest://?label=docs&label=data", closed, false},
+		{"githubpullrequest://?exclude_label=flights", opened, true},
+		{"githubpullrequest://?exclude_label=flights", closed, false},
+		{"githubpullrequest://?halt_if=author%3D%3Dthisisaaronland", opened, true},
+		{"githubpullrequest://?only_if=merged%3D%3Dtrue", closed, false},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+}
+
+func TestGitHubPullRequestTransformationWithFilesHalted(t *testing.T) {
+
+	requests := 0
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		requests += 1
+		http.Error(rsp, "Internal server error", http.StatusInternalServerError)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	body := readFixture(t, "fixtures/events/pull_request.json")
+
+	ctx := context.Background()
+
+	q := url.Values{}
+	q.Set("include_files", "true")
+	q.Set("api_base_url", ts.URL)
+	q.Set("halt_if", "author==thisisaaronland")
+	q.Set("only_if", "path=~^data/")
+
+	tr, err := transformation.NewTransformation(ctx, "githubpullrequest://?"+q.Encode())
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	_, err2 := tr.Transform(ctx, body)
+
+	if err2 == nil || err2.Code != webhookd.HaltEvent {
+		t.Fatalf("Expected halt event, got %v", err2)
+	}
+
+	if requests != 0 {
+		t.Fatalf("Expected no API requests for halted event, got %d", requests)
+	}
+}
+
+func newPullRequestFilesTestServer(count int, token string) *httptest.Server {
+
+	per_page := 100
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+
+		if req.Header.Get("Authorization") != fmt.Sprintf("token %s", token) {
+			http.Error(rsp, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if req.URL.Path != "/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/pulls/42/files" {
+			http.Error(rsp, "Not found", http.StatusNotFound)
+			return
+		}
+
+		page := 1
+		q := req.URL.Query()
+
+		if q.Get("page") != "" {
+
+			v, err := strconv.Atoi(q.Get("page"))
+
+			if err != nil {
+				http.Error(rsp, "Bad request", http.StatusBadRequest)
+				return
+			}
+
+			page = v
+		}
+
+		files := make([]map[string]interface{}, 0)
+
+		for i := (page - 1) * per_page; i < page*per_page && i < count; i++ {
+
+			f := map[string]interface{}{
+				"filename":  fmt.Sprintf("data/%d.geojson", i),
+				"status":    "modified",
+				"additions": 2,
+				"deletions": 1,
+			}
+
+			files = append(files, f)
+		}
+
+		if page*per_page < count {
+			next := *req.URL
+			next_q := next.Query()
+			next_q.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = next_q.Encode()
+			rsp.Header().Set("Link", fmt.Sprintf("<http://%s%s>; rel=\"next\"", req.Host, next.String()))
+		}
+
+		rsp.Header().Set("Content-Type", "application/json")
+
+		enc := json.NewEncoder(rsp)
+		enc.Encode(files)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+func TestGitHubPullRequestTransformationWithFiles(t *testing.T) {
+
+	token := "s33kret"
+	count := 250
+
+	ts := newPullRequestFilesTestServer(count, token)
+	defer ts.Close()
+
+	body := readFixture(t, "fixtures/events/pull_request.json")
+
+	ctx := context.Background()
+
+	q := url.Values{}
+	q.Set("include_files", "true")
+	q.Set("api_token", token)
+	q.Set("api_base_url", ts.URL)
+
+	tr, err := transformation.NewTransformation(ctx, "githubpullrequest://?"+q.Encode())
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	rows := strings.Split(strings.TrimSpace(string(rsp)), "\n")
+
+	if len(rows) != count {
+		t.Fatalf("Unexpected number of rows: %d", len(rows))
+	}
+
+	if rows[0] != "sfomuseum-data-flights-2020-05,42,7d3c0a1b9e8f6a5d4c3b2a1f0e9d8c7b6a5f4e3d,modified,data/0.geojson" {
+		t.Fatalf("Unexpected first row: %s", rows[0])
+	}
+
+	q.Set("format", "json")
+	q.Add("only_if", "path=~^data/249")
+
+	tr, err = transformation.NewTransformation(ctx, "githubpullrequest://?"+q.Encode())
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 = tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec PullRequestRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	if len(rec.Files) != count {
+		t.Fatalf("Unexpected number of files: %d", len(rec.Files))
+	}
+
+	q.Set("api_token", "wrong")
+
+	tr, err = transformation.NewTransformation(ctx, "githubpullrequest://?"+q.Encode())
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	_, err2 = tr.Transform(ctx, body)
+
+	if err2 == nil || err2.Code != http.StatusBadGateway {
+		t.Fatalf("Expected bad gateway error, got %v", err2)
+	}
+}
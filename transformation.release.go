@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubrelease", NewGitHubReleaseTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubReleaseTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub `release`
+// webhook messages, and `create` and `delete` webhook messages for tags, in to release metadata.
+type GitHubReleaseTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// ExcludeDrafts is a boolean flag to halt processing of events for draft releases.
+	ExcludeDrafts bool
+	// ExcludePrereleases is a boolean flag to halt processing of events for pre-releases.
+	ExcludePrereleases bool
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of tag patterns to process. If empty all tags are processed.
+	tags []string
+}
+
+// releaseEvents defines how `release`, `create` and `delete` events are handled by the `GitHubReleaseTransformation`.
+var releaseEvents = &eventHandler[*ReleaseRecord]{
+	decoders: map[string]eventDecoder[*ReleaseRecord]{
+		"release": decodeEventAs(newReleaseRecordFromRelease),
+		"create":  decodeEventAs(newReleaseRecordFromCreate),
+		"delete":  decodeEventAs(newReleaseRecordFromDelete),
+	},
+	rule_field_names: releaseRuleFieldNames,
+	rule_fields:      releaseRuleFields,
+	rows:             releaseRows,
+}
+
+// NewGitHubReleaseTransformation() creates a new `GitHubReleaseTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubrelease://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "release", "create", "delete" or "infer" to infer the event type from each message. Default is "release".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process, for example "published", "prereleased" or "edited". `create` and `delete` events have the actions "created" and "deleted". Default is all actions.
+// * `?tag` Zero or more `path.Match` patterns (for example "v*") for the tags to process. Default is all tags.
+// * `?exclude_drafts` An optional boolean value to skip draft releases.
+// * `?exclude_prereleases` An optional boolean value to skip pre-releases.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed, including `create` and `delete` events for branches, cause the transformer to return an error
+// with code `webhookd.HaltEvent`.
+func NewGitHubReleaseTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "release", releaseEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubReleaseTransformation{
+		eventParams: params,
+		actions:     parseListParam(q, "action"),
+		tags:        parseListParam(q, "tag"),
+	}
+
+	flags := map[string]*bool{
+		"exclude_drafts":      &p.ExcludeDrafts,
+		"exclude_prereleases": &p.ExcludePrereleases,
+	}
+
+	err = parseBoolParams(q, flags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `release` webhook message, or a `create` or `delete` webhook
+// message for a tag) in to CSV data containing: the name of the repository, the tag, the action, the target commitish, the asset
+// name and the asset download URL. There is one row for each asset; releases without assets (and tags) produce a single row with
+// empty asset columns. If 'p' was created with `?format=json` or `?format=ndjson` the output will be a JSON-encoded `ReleaseRecord`.
+func (p *GitHubReleaseTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, releaseEvents, p.filter, body)
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' is derived from a `create` or `delete` event
+// for a ref which is not a tag or does not match the action, tag, draft and pre-release filters used to create 'p'. Otherwise it
+// returns nil.
+func (p *GitHubReleaseTransformation) filter(rec *ReleaseRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	switch {
+	case rec.ref_type != "" && rec.ref_type != REF_TYPE_TAG:
+		msg = fmt.Sprintf("Halt (ref_type %s)", rec.ref_type)
+	case !matchesPattern(rec.Action, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case !matchesPattern(rec.Tag, p.tags):
+		msg = fmt.Sprintf("Halt (tag %s)", rec.Tag)
+	case p.ExcludeDrafts && rec.Draft:
+		msg = "Halt (draft)"
+	case p.ExcludePrereleases && rec.Prerelease:
+		msg = "Halt (prerelease)"
+	default:
+		return nil
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
@@ -0,0 +1,190 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubReleaseTransformation(t *testing.T) {
+
+	expected := []byte(`sfomuseum-data-flights-2020-05,v2020.05.1,published,main,sfomuseum-data-flights-2020-05.tar.bz2,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/releases/download/v2020.05.1/sfomuseum-data-flights-2020-05.tar.bz2
+sfomuseum-data-flights-2020-05,v2020.05.1,published,main,sfomuseum-data-flights-2020-05.csv,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/releases/download/v2020.05.1/sfomuseum-data-flights-2020-05.csv
+`)
+
+	body := readFixture(t, "fixtures/events/release.json")
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubrelease://")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	if !bytes.Equal(rsp, expected) {
+		t.Fatalf("Unexpected output: '%s'", string(rsp))
+	}
+}
+
+func TestGitHubReleaseTransformationWithTags(t *testing.T) {
+
+	body := readFixture(t, "fixtures/events/create_tag.json")
+
+	ctx := context.Background()
+
+	for _, uri := range []string{"githubrelease://?event=create", "githubrelease://?event=infer"} {
+
+		tr, err := transformation.NewTransformation(ctx, uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform message for %s, %v", uri, err2)
+		}
+
+		if string(rsp) != "sfomuseum-data-flights-2020-05,v2020.05.1,created,,,\n" {
+			t.Fatalf("Unexpected output for %s: '%s'", uri, string(rsp))
+		}
+	}
+
+	branch := bytes.Replace(body, []byte(`"ref_type": "tag"`), []byte(`"ref_type": "branch"`), 1)
+
+	tr, err := transformation.NewTransformation(ctx, "githubrelease://?event=create")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	_, err2 := tr.Transform(ctx, branch)
+
+	if err2 == nil || err2.Code != webhookd.HaltEvent {
+		t.Fatalf("Expected halt event for branch, got %v", err2)
+	}
+
+	tr, err = transformation.NewTransformation(ctx, "githubrelease://?event=delete&format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec ReleaseRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	if rec.Event != "delete" || rec.Action != "deleted" || rec.Tag != "v2020.05.1" || rec.Author != "sfomuseumbot" {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+}
+
+func TestGitHubReleaseTransformationWithJSON(t *testing.T) {
+
+	body := readFixture(t, "fixtures/events/release.json")
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubrelease://?format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec ReleaseRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	if rec.Schema != RELEASE_SCHEMA || rec.Tag != "v2020.05.1" || rec.Target != "main" || rec.Name != "May 2020 flights (1)" {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+
+	if len(rec.Assets) != 2 || rec.Assets[1].Size != 2318842 {
+		t.Fatalf("Unexpected assets: %v", rec.Assets)
+	}
+}
+
+func TestGitHubReleaseTransformationWithFilters(t *testing.T) {
+
+	published := readFixture(t, "fixtures/events/release.json")
+	draft := bytes.Replace(published, []byte(`"draft": false`), []byte(`"draft": true`), 1)
+	prerelease := bytes.Replace(published, []byte(`"prerelease": false`), []byte(`"prerelease": true`), 1)
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubrelease://?action=published,prereleased", published, false},
+		{"githubrelease://?action=edited", published, true},
+		{"githubrelease://?tag=v*", published, false},
+		{"githubrelease://?tag=data-*", published, true},
+		{"githubrelease://?exclude_drafts=true", published, false},
+		{"githubrelease://?exclude_drafts=true", draft, true},
+		{"githubrelease://?exclude_prereleases=true", prerelease, true},
+		{"githubrelease://?exclude_prereleases=true", draft, false},
+		{"githubrelease://?only_if=asset*%3D*.csv", published, false},
+		{"githubrelease://?halt_if=author%3D%3Dsfomuseumbot", published, true},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+
+	_, err := transformation.NewTransformation(ctx, "githubrelease://?event=push")
+
+	if err == nil {
+		t.Fatalf("Expected error for unsupported event type")
+	}
+}
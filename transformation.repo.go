@@ -3,10 +3,10 @@ package github
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"net/url"
-	"regexp"
 	"strconv"
 
 	gogithub "github.com/google/go-github/v48/github"
@@ -40,10 +40,12 @@ type GitHubRepoTransformation struct {
 	prepend_message bool
 	// A boolean flag signaling the commit author should be prepended to the top of the final output in the form of '#author {COMMIT_AUTHOR}'
 	prepend_author bool
-	// An optional regular expression that will be compared to the commit message; if it matches the transformer will return an error with code `webhookd.HaltEvent`
-	halt_on_message *regexp.Regexp
-	// An optional regular expression that will be compared to the commit author; if it matches the transformer will return an error with code `webhookd.HaltEvent`
-	halt_on_author *regexp.Regexp
+	// The set of rules used to determine whether the transformer should return an error with code `webhookd.HaltEvent`. This includes the `?halt_on_message` and `?halt_on_author` parameters.
+	rules *RuleSet
+	// The format of the final output. Valid options are `FORMAT_CSV`, `FORMAT_JSON` and `FORMAT_NDJSON`.
+	format string
+	// The list of `RepoRecord` fields to include in CSV output. If empty only the name of the repository is output.
+	fields []string
 }
 
 // NewGitHubRepoTransformation() creates a new `GitHubRepoTransformation` instance, configured by 'uri'
@@ -59,6 +61,10 @@ type GitHubRepoTransformation struct {
 // * `?prepend_author` An optional boolean value to prepend the name of the commit author to the final output. This takes the form of '#author {COMMIT_AUTHOR}'
 // * `?halt_on_message` An optional regular expression that will be compared to the commit message; if it matches the transformer will return an error with code `webhookd.HaltEvent`
 // * `?halt_on_author` An optional regular expression that will be compared to the commit author; if it matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+// * `?fields` An optional comma-separated list of fields to include in CSV output. Valid options are: name, full_name, ref, branch, before, after, added, modified, removed. Default is name.
 func NewGitHubRepoTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
 
 	u, err := url.Parse(uri)
@@ -78,6 +84,18 @@ func NewGitHubRepoTransformation(ctx context.Context, uri string) (webhookd.Webh
 	q_halt_on_message := q.Get("halt_on_message")
 	q_halt_on_author := q.Get("halt_on_author")
 
+	format, err := parseFormat(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := parseRepoFields(q.Get("fields"))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse ?fields= parameter, %w", err)
+	}
+
 	exclude_additions := false
 	exclude_modifications := false
 	exclude_deletions := false
@@ -146,35 +164,52 @@ func NewGitHubRepoTransformation(ctx context.Context, uri string) (webhookd.Webh
 		ExcludeDeletions:     exclude_deletions,
 		prepend_message:      prepend_message,
 		prepend_author:       prepend_author,
+		format:               format,
+		fields:               fields,
+	}
+
+	rules, err := NewRuleSetFromQuery(q)
+
+	if err != nil {
+		return nil, err
 	}
 
 	if q_halt_on_message != "" {
 
-		r, err := regexp.Compile(q_halt_on_message)
+		r, err := ParseRule(fmt.Sprintf("head_message%s%s", OPERATOR_MATCHES, q_halt_on_message))
 
 		if err != nil {
 			return nil, fmt.Errorf("Failed to parse ?halt_on_message= parameter, %w", err)
 		}
 
-		p.halt_on_message = r
+		rules.Halt = append(rules.Halt, r)
 	}
 
 	if q_halt_on_author != "" {
 
-		r, err := regexp.Compile(q_halt_on_author)
+		r, err := ParseRule(fmt.Sprintf("head_author%s%s", OPERATOR_MATCHES, q_halt_on_author))
 
 		if err != nil {
 			return nil, fmt.Errorf("Failed to parse ?halt_on_author= parameter, %w", err)
 		}
 
-		p.halt_on_author = r
+		rules.Halt = append(rules.Halt, r)
+	}
+
+	err = rules.Validate(pushRuleFieldNames)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid rules, %w", err)
 	}
 
+	p.rules = rules
+
 	return &p, nil
 }
 
 // Transform() transforms 'body' (which is assumed to be a GitHub commit webhook message) in to name of the repository
-// where the commit occurred.
+// where the commit occurred. If 'p' was created with `?fields=` then the output will be a CSV row containing those fields.
+// If 'p' was created with `?format=json` or `?format=ndjson` then the output will be a JSON-encoded `RepoRecord`.
 func (p *GitHubRepoTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
 
 	select {
@@ -193,61 +228,49 @@ func (p *GitHubRepoTransformation) Transform(ctx context.Context, body []byte) (
 		return nil, err
 	}
 
-	if p.halt_on_message != nil && p.halt_on_message.MatchString(*event.HeadCommit.Message) {
-		err := &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: "Halt"}
-		return nil, err
-	}
+	changes := pushChanges(&event)
 
-	if p.halt_on_author != nil && p.halt_on_author.MatchString(*event.HeadCommit.Author.Name) {
-		err := &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: "Halt"}
-		return nil, err
+	halt_err := p.rules.Evaluate(pushRuleFields(&event, changes))
+
+	if halt_err != nil {
+		return nil, halt_err
 	}
 
 	buf := new(bytes.Buffer)
 
-	repo := event.Repo
-	repo_name := *repo.Name
-
-	has_updates := false
-
-	for _, c := range event.Commits {
+	changes = filterChanges(changes, p.ExcludeAdditions, p.ExcludeModifications, p.ExcludeDeletions, false)
 
-		if !p.ExcludeAdditions {
-
-			if len(c.Added) > 0 {
-				has_updates = true
-			}
-		}
-
-		if !p.ExcludeModifications {
-
-			if len(c.Modified) > 0 {
-				has_updates = true
-			}
-		}
+	if len(changes) == 0 {
+		return buf.Bytes(), nil
+	}
 
-		if !p.ExcludeDeletions {
+	rec := newRepoRecord(&event, changes)
 
-			if len(c.Removed) > 0 {
-				has_updates = true
-			}
-		}
+	switch p.format {
+	case FORMAT_JSON, FORMAT_NDJSON:
+		return marshalJSON(rec)
+	default:
+		// pass
 	}
 
-	if has_updates {
-
-		if p.prepend_message {
-			msg := fmt.Sprintf("#message %s\n", *event.HeadCommit.Message)
-			buf.WriteString(msg)
-		}
+	if p.prepend_message {
+		msg := fmt.Sprintf("#message %s\n", *event.HeadCommit.Message)
+		buf.WriteString(msg)
+	}
 
-		if p.prepend_author {
-			msg := fmt.Sprintf("#author %s\n", *event.HeadCommit.Author.Name)
-			buf.WriteString(msg)
-		}
+	if p.prepend_author {
+		msg := fmt.Sprintf("#author %s\n", *event.HeadCommit.Author.Name)
+		buf.WriteString(msg)
+	}
 
-		buf.WriteString(repo_name)
+	if len(p.fields) == 0 {
+		buf.WriteString(rec.Repo)
+		return buf.Bytes(), nil
 	}
 
+	wr := csv.NewWriter(buf)
+	wr.Write(rec.Values(p.fields))
+	wr.Flush()
+
 	return buf.Bytes(), nil
 }
@@ -3,6 +3,7 @@ package github
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"os"
 	"testing"
@@ -125,3 +126,125 @@ func TestGitHubRepoTransformationWithHalt(t *testing.T) {
 		t.Fatalf("Expected halt event")
 	}
 }
+
+func TestGitHubRepoTransformationWithJSON(t *testing.T) {
+
+	expected_repo := "sfomuseum-data-flights-2020-05"
+	expected_author := "sfomuseumbot"
+
+	msg := "fixtures/events/flights.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubrepo://?format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	output, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec RepoRecord
+
+	err = json.Unmarshal(output, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal JSON output, %v", err)
+	}
+
+	if rec.Repo != expected_repo {
+		t.Fatalf("Unexpected repo: %s", rec.Repo)
+	}
+
+	if rec.Author != expected_author {
+		t.Fatalf("Unexpected author: %s", rec.Author)
+	}
+
+	if rec.FullName != "sfomuseum-data/sfomuseum-data-flights-2020-05" {
+		t.Fatalf("Unexpected full name: %s", rec.FullName)
+	}
+
+	if rec.Added != 1496 || rec.Modified != 111 || rec.Removed != 0 {
+		t.Fatalf("Unexpected change counts: %d, %d, %d", rec.Added, rec.Modified, rec.Removed)
+	}
+}
+
+func TestGitHubRepoTransformationWithFields(t *testing.T) {
+
+	expected_repo := []byte("sfomuseum-data/sfomuseum-data-flights-2020-05,main,4f7ea05db12b94d765e594f396924812433a4518,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,1496,111,0\n")
+
+	msg := "fixtures/events/flights.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubrepo://?fields=full_name,branch,before,after,added,modified,removed")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	output, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	if !bytes.Equal(output, expected_repo) {
+		t.Fatalf("Unexpected output: '%s'", string(output))
+	}
+}
+
+func TestGitHubRepoTransformationWithFieldsAndExclusions(t *testing.T) {
+
+	expected_repo := []byte("sfomuseum-data-flights-2020-05,0,111,0\n")
+
+	body := readFixture(t, "fixtures/events/flights.json")
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubrepo://?fields=name,added,modified,removed&exclude_additions=true")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	output, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	if !bytes.Equal(output, expected_repo) {
+		t.Fatalf("Unexpected output: '%s'", string(output))
+	}
+}
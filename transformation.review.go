@@ -0,0 +1,131 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubreview", NewGitHubReviewTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubReviewTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub `pull_request_review`,
+// `pull_request_review_comment` and `pull_request_review_thread` webhook messages in to pull request review metadata.
+type GitHubReviewTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of (lower-case) review states to process. If empty all states are processed.
+	states []string
+	// The list of reviewer patterns to process. If empty all reviewers are processed.
+	reviewers []string
+	// The list of reviewer patterns to exclude.
+	exclude_reviewers []string
+	// The list of file path patterns to process. If empty all paths are processed.
+	paths []string
+}
+
+// reviewEvents defines how `pull_request_review`, `pull_request_review_comment` and `pull_request_review_thread` events are handled
+// by the `GitHubReviewTransformation`.
+var reviewEvents = &eventHandler[*ReviewRecord]{
+	decoders: map[string]eventDecoder[*ReviewRecord]{
+		"pull_request_review":         decodeEventAs(newReviewRecordFromReview),
+		"pull_request_review_comment": decodeEventAs(newReviewRecordFromComment),
+		"pull_request_review_thread":  decodeEventAs(newReviewRecordFromThread),
+	},
+	rule_field_names: reviewRuleFieldNames,
+	rule_fields:      reviewRuleFields,
+	rows:             reviewRows,
+}
+
+// NewGitHubReviewTransformation() creates a new `GitHubReviewTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubreview://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "pull_request_review", "pull_request_review_comment", "pull_request_review_thread" or "infer" to infer the event type from each message. Default is "pull_request_review".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process, for example "submitted", "created" or "resolved". Default is all actions.
+// * `?state` Zero or more review states to process, for example "approved", "changes_requested" or "commented". Only `pull_request_review` events have a state so other events are never processed if this parameter is present. Default is all states.
+// * `?reviewer` Zero or more `path.Match` patterns for the logins of the reviewers to process. Default is all reviewers.
+// * `?exclude_reviewer` Zero or more `path.Match` patterns for the logins of the reviewers to exclude, for example "dependabot*".
+// * `?path` Zero or more `path.Match` patterns for the paths of the files commented on to process. `pull_request_review` events have no path so they are never processed if this parameter is present. Default is all paths.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubReviewTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "pull_request_review", reviewEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubReviewTransformation{
+		eventParams:       params,
+		actions:           parseListParam(q, "action"),
+		states:            parseListParam(q, "state"),
+		reviewers:         parseListParam(q, "reviewer"),
+		exclude_reviewers: parseListParam(q, "exclude_reviewer"),
+		paths:             parseListParam(q, "path"),
+	}
+
+	for idx, s := range p.states {
+		p.states[idx] = strings.ToLower(s)
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `pull_request_review`, `pull_request_review_comment` or
+// `pull_request_review_thread` webhook message) in to CSV data containing: the name of the repository, the pull request number,
+// the name of the event, the reviewer, the review state, the path and line of the file commented on and the URL of the review
+// or comment. If 'p' was created with `?format=json` or `?format=ndjson` the output will be a JSON-encoded `ReviewRecord`.
+func (p *GitHubReviewTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, reviewEvents, p.filter, body)
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' does not match the action, state,
+// reviewer and path filters used to create 'p'. Otherwise it returns nil.
+func (p *GitHubReviewTransformation) filter(rec *ReviewRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	switch {
+	case !matchesPattern(rec.Action, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case !matchesPattern(rec.State, p.states):
+		msg = fmt.Sprintf("Halt (state %s)", rec.State)
+	case !matchesFilters(rec.Reviewer, p.reviewers, p.exclude_reviewers):
+		msg = fmt.Sprintf("Halt (reviewer %s)", rec.Reviewer)
+	case !matchesPattern(rec.Path, p.paths):
+		msg = fmt.Sprintf("Halt (path %s)", rec.Path)
+	default:
+		return nil
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
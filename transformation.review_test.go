@@ -0,0 +1,162 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	gogithub "github.com/google/go-github/v48/github"
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubReviewTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri      string
+		msg      string
+		expected string
+	}{
+		{
+			"githubreview://",
+			"fixtures/events/pull_request_review.json",
+			"sfomuseum-data-flights-2020-05,42,pull_request_review,straup,changes_requested,,,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/pull/42#pullrequestreview-1463127791\n",
+		},
+		{
+			"githubreview://?event=pull_request_review_comment",
+			"fixtures/events/pull_request_review_comment.json",
+			"sfomuseum-data-flights-2020-05,42,pull_request_review_comment,straup,,data/171/316/450/9/1713164509.geojson,15,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/pull/42#discussion_r1211459923\n",
+		},
+		{
+			"githubreview://?event=infer",
+			"fixtures/events/pull_request_review_thread.json",
+			"sfomuseum-data-flights-2020-05,42,pull_request_review_thread,straup,,data/171/316/450/9/1713164509.geojson,15,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/pull/42#discussion_r1211459923\n",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		body := readFixture(t, test.msg)
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		if string(rsp) != test.expected {
+			t.Fatalf("Unexpected output for %s: '%s'", test.msg, string(rsp))
+		}
+	}
+}
+
+func TestGitHubReviewTransformationWithJSON(t *testing.T) {
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubreview://?event=pull_request_review_thread&format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, readFixture(t, "fixtures/events/pull_request_review_thread.json"))
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec ReviewRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal record, %v", err)
+	}
+
+	if rec.Schema != REVIEW_SCHEMA || rec.Action != "resolved" || rec.Comments != 2 || rec.ReviewID != 1463127791 {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+
+	if rec.Reviewer != "straup" || rec.Actor != "thisisaaronland" || rec.Author != "thisisaaronland" {
+		t.Fatalf("Unexpected users: %v", rec)
+	}
+}
+
+func TestGitHubReviewTransformationWithFilters(t *testing.T) {
+
+	review := readFixture(t, "fixtures/events/pull_request_review.json")
+	approved := bytes.Replace(review, []byte(`"state": "changes_requested"`), []byte(`"state": "APPROVED"`), 1)
+
+	comment := readFixture(t, "fixtures/events/pull_request_review_comment.json")
+	outdated := bytes.Replace(comment, []byte(`"line": 15`), []byte(`"line": null`), 1)
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubreview://?state=changes_requested&reviewer=straup", review, false},
+		{"githubreview://?state=CHANGES_REQUESTED", approved, true},
+		{"githubreview://?state=approved", approved, false},
+		{"githubreview://?reviewer=thisisaaronland", review, true},
+		{"githubreview://?exclude_reviewer=str*", review, true},
+		{"githubreview://?path=data/*", review, true},
+		{"githubreview://?event=pull_request_review_comment&path=data/*/*/*/*/*.geojson&action=created", comment, false},
+		{"githubreview://?event=pull_request_review_comment&path=docs/*", comment, true},
+		{"githubreview://?event=pull_request_review_comment&state=changes_requested", comment, true},
+		{"githubreview://?event=pull_request_review_comment&only_if=path%3D~%5Edata/", outdated, false},
+		{"githubreview://?halt_if=association%3D%3DMEMBER", review, true},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+}
+
+func TestNewReviewRecordWithOutdatedComment(t *testing.T) {
+
+	body := readFixture(t, "fixtures/events/pull_request_review_comment.json")
+	body = bytes.Replace(body, []byte(`"line": 15`), []byte(`"line": null`), 1)
+	body = bytes.Replace(body, []byte(`"original_line": 15`), []byte(`"original_line": 12`), 1)
+
+	event, err := UnmarshalEventAs[gogithub.PullRequestReviewCommentEvent](body)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal event, %v", err)
+	}
+
+	rec := newReviewRecordFromComment(event)
+
+	if rec.Line != 12 {
+		t.Fatalf("Expected original line for outdated comment, got %d", rec.Line)
+	}
+}
@@ -0,0 +1,146 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubsecurity", NewGitHubSecurityTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubSecurityTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub `code_scanning_alert`,
+// `secret_scanning_alert`, `repository_vulnerability_alert` and `security_advisory` webhook messages in to normalized security alerts.
+type GitHubSecurityTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// A boolean flag to redact secret values and locations from secret scanning alerts. Default is true.
+	redact bool
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of states to process. If empty all states are processed.
+	states []string
+	// The minimum (normalized) severity of alerts to process. If empty all alerts are processed.
+	min_severity string
+}
+
+// securityEvents defines how `code_scanning_alert`, `secret_scanning_alert`, `repository_vulnerability_alert` and `security_advisory`
+// events are handled by the `GitHubSecurityTransformation`.
+var securityEvents = &eventHandler[*SecurityRecord]{
+	decoders: map[string]eventDecoder[*SecurityRecord]{
+		"code_scanning_alert":            decodeEventAs(newSecurityRecordFromCodeScanning),
+		"secret_scanning_alert":          decodeEventAs(newSecurityRecordFromSecretScanning),
+		"repository_vulnerability_alert": decodeEventAs(newSecurityRecordFromVulnerability),
+		"security_advisory":              decodeEventAs(newSecurityRecordFromAdvisory),
+	},
+	rule_field_names: securityRuleFieldNames,
+	rule_fields:      securityRuleFields,
+	rows:             securityRows,
+}
+
+// NewGitHubSecurityTransformation() creates a new `GitHubSecurityTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubsecurity://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "code_scanning_alert", "secret_scanning_alert", "repository_vulnerability_alert", "security_advisory" or "infer" to infer the event type from each message. Default is "code_scanning_alert".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process, for example "created", "reopened", "create" or "published". Default is all actions.
+// * `?state` Zero or more states to process, for example "open", "dismissed", "fixed" or "resolved". Default is all states.
+// * `?min_severity` The minimum severity of alerts to process: "low", "medium", "high" or "critical". Default is all severities.
+// * `?redact` An optional boolean value to redact secret values and locations from secret scanning alerts. Default is true.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubSecurityTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "code_scanning_alert", securityEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubSecurityTransformation{
+		eventParams: params,
+		redact:      true,
+		actions:     parseListParam(q, "action"),
+		states:      parseListParam(q, "state"),
+	}
+
+	if q.Get("min_severity") != "" {
+
+		min_severity, err := parseSeverity(q.Get("min_severity"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?min_severity= parameter, %w", err)
+		}
+
+		p.min_severity = min_severity
+	}
+
+	flags := map[string]*bool{
+		"redact": &p.redact,
+	}
+
+	err = parseBoolParams(q, flags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `code_scanning_alert`, `secret_scanning_alert`, `repository_vulnerability_alert`
+// or `security_advisory` webhook message) in to CSV data containing: the name of the event, the name of the repository, the alert ID, the
+// normalized severity, the state, the title, the location and the URL. If 'p' was created with `?format=json` or `?format=ndjson` the
+// output will be a JSON-encoded `SecurityRecord`.
+func (p *GitHubSecurityTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, securityEvents, p.filter, body)
+}
+
+// filter redacts 'rec' (unless 'p' was created with `?redact=false`) and then returns a `webhookd.WebhookError` with code
+// `webhookd.HaltEvent` if 'rec' does not match the action, state and severity filters used to create 'p'. Otherwise it returns
+// nil. Redaction happens here, before rules are evaluated or output is encoded, so that secrets never reach either.
+func (p *GitHubSecurityTransformation) filter(rec *SecurityRecord) *webhookd.WebhookError {
+
+	if p.redact {
+		redactSecurityRecord(rec)
+	}
+
+	var msg string
+
+	switch {
+	case !matchesPattern(rec.Action, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case !matchesPattern(rec.State, p.states):
+		msg = fmt.Sprintf("Halt (state %s)", rec.State)
+	case !meetsSeverity(rec.Severity, p.min_severity):
+		msg = fmt.Sprintf("Halt (severity %s)", rec.Severity)
+	default:
+		return nil
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
@@ -0,0 +1,246 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	gogithub "github.com/google/go-github/v48/github"
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+	"gocloud.dev/runtimevar"
+	_ "gocloud.dev/runtimevar/constantvar"
+	_ "gocloud.dev/runtimevar/filevar"
+)
+
+// TemplateError is the `webhookd.WebhookError` code returned when a template fails to render a webhook message.
+const TemplateError int = http.StatusUnprocessableEntity
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubtemplate", NewGitHubTemplateTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// TemplateData is the data passed to the templates used by `GitHubTemplateTransformation`.
+type TemplateData struct {
+	// EventType is the name of the GitHub event, for example "push".
+	EventType string
+	// Event is the GitHub event, as a pointer to its corresponding go-github type, for example `*github.PushEvent`.
+	Event interface{}
+}
+
+// templateFuncs returns the functions available to the templates used by `GitHubTemplateTransformation`.
+func templateFuncs() template.FuncMap {
+
+	funcs := template.FuncMap{
+		"shortsha":   templateShortSHA,
+		"join":       templateJoin,
+		"paths":      templatePaths,
+		"json":       templateJSON,
+		"trimprefix": templateTrimPrefix,
+	}
+
+	return funcs
+}
+
+// templateShortSHA returns the first seven characters of 'sha'.
+func templateShortSHA(sha string) string {
+
+	if len(sha) <= 7 {
+		return sha
+	}
+
+	return sha[0:7]
+}
+
+// templateJoin returns the elements of 'items' joined by 'sep'. The order of the arguments allows the function to be used in a pipeline.
+func templateJoin(sep string, items []string) string {
+	return strings.Join(items, sep)
+}
+
+// templateTrimPrefix returns 's' without the leading 'prefix'. The order of the arguments allows the function to be used in a pipeline.
+func templateTrimPrefix(prefix string, s string) string {
+	return strings.TrimPrefix(s, prefix)
+}
+
+// templateJSON returns the JSON encoding of 'v'.
+func templateJSON(v interface{}) (string, error) {
+
+	enc, err := json.Marshal(v)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(enc), nil
+}
+
+// templatePaths returns the paths of the files in 'event', which must be a `*github.PushEvent`, whose action is 'action'
+// ("added", "modified" or "removed"). If 'action' is "all" every path is returned.
+func templatePaths(action string, event interface{}) ([]string, error) {
+
+	push, ok := event.(*gogithub.PushEvent)
+
+	if !ok {
+		return nil, fmt.Errorf("paths is only supported for push events, not %T", event)
+	}
+
+	paths := make([]string, 0)
+
+	for _, ch := range pushChanges(push) {
+
+		if action == "all" || ch.Action == action {
+			paths = append(paths, ch.Path)
+		}
+	}
+
+	return paths, nil
+}
+
+// GitHubTemplateTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub
+// webhook messages using a Go language `text/template` template.
+type GitHubTemplateTransformation struct {
+	webhookd.WebhookTransformation
+	// The name of the GitHub event that webhook messages are expected to be.
+	event_type string
+	// The minimum confidence required for an inferred event type to be accepted, if 'event_type' is `EVENT_INFER`.
+	min_confidence Confidence
+	// The template used to transform webhook messages.
+	template *template.Template
+}
+
+// NewGitHubTemplateTransformation() creates a new `GitHubTemplateTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubtemplate://?template_uri={URI}&event={EVENT}
+//
+// Where {PARAMTERS} is:
+// * `?template_uri` A valid `gocloud.dev/runtimevar` URI whose value is a Go language `text/template` template. Required.
+// * `?event` The name of the GitHub event that webhook messages are expected to be, or "infer" to infer the event type from each message. Default is "push".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+//
+// Templates are passed a `TemplateData` instance and have access to the following functions: `shortsha`, `join`,
+// `paths`, `json` and `trimprefix`.
+func NewGitHubTemplateTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	template_uri := q.Get("template_uri")
+
+	if template_uri == "" {
+		return nil, fmt.Errorf("Missing ?template_uri= parameter")
+	}
+
+	event_type, min_confidence, err := parseEventParams(q, "push")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if event_type != EVENT_INFER {
+
+		err := validateEventType(event_type)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?event= parameter, %w", err)
+		}
+	}
+
+	v, err := runtimevar.OpenVariable(ctx, template_uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open template URI, %w", err)
+	}
+
+	defer v.Close()
+
+	latest, err := v.Latest(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to determine latest value for template URI, %w", err)
+	}
+
+	var str_t string
+
+	switch latest.Value.(type) {
+	case string:
+		str_t = latest.Value.(string)
+	case []byte:
+		str_t = string(latest.Value.([]byte))
+	default:
+		return nil, fmt.Errorf("Unsupported value type for template URI, %T", latest.Value)
+	}
+
+	t, err := template.New("githubtemplate").Funcs(templateFuncs()).Parse(str_t)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse template, %w", err)
+	}
+
+	p := GitHubTemplateTransformation{
+		event_type:     event_type,
+		min_confidence: min_confidence,
+		template:       t,
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub webhook message of the event type used to create 'p')
+// by rendering it with the template used to create 'p'. Template execution errors are returned as a `webhookd.WebhookError`
+// with code `TemplateError`.
+func (p *GitHubTemplateTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+
+	select {
+	case <-ctx.Done():
+		return nil, nil
+	default:
+		// pass
+	}
+
+	event_type, err2 := resolveEventType(p.event_type, p.min_confidence, body)
+
+	if err2 != nil {
+		return nil, err2
+	}
+
+	event, err := UnmarshalEvent(event_type, body)
+
+	if err != nil {
+		err := &webhookd.WebhookError{Code: 999, Message: err.Error()}
+		return nil, err
+	}
+
+	data := TemplateData{
+		EventType: event_type,
+		Event:     event,
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = p.template.Execute(buf, data)
+
+	if err != nil {
+		msg := fmt.Sprintf("Failed to render template, %v", err)
+		err := &webhookd.WebhookError{Code: TemplateError, Message: msg}
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
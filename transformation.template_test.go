@@ -0,0 +1,143 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubTemplateTransformation(t *testing.T) {
+
+	expected := "sfomuseum-data/sfomuseum-data-flights-2020-05 main 9b1c3a0\narchive/171/316/450/9/1713164509.geojson,data/171/316/451/9/1713164519.geojson\n"
+
+	msg := "fixtures/events/rename.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	path_t, err := filepath.Abs("fixtures/templates/push.txt")
+
+	if err != nil {
+		t.Fatalf("Failed to derive absolute path for template, %v", err)
+	}
+
+	template_uri := fmt.Sprintf("file://%s?decoder=string", path_t)
+
+	ctx := context.Background()
+
+	tr_uri := fmt.Sprintf("githubtemplate://?template_uri=%s", url.QueryEscape(template_uri))
+
+	tr, err := transformation.NewTransformation(ctx, tr_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	if string(rsp) != expected {
+		t.Fatalf("Unexpected output: '%s'", string(rsp))
+	}
+}
+
+func TestGitHubTemplateTransformationWithError(t *testing.T) {
+
+	msg := "fixtures/events/rename.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	// paths is only supported for push events so this will fail at execution time
+	template_uri := fmt.Sprintf("constant://?decoder=string&val=%s", url.QueryEscape(`{{ paths "added" .EventType }}`))
+	tr_uri := fmt.Sprintf("githubtemplate://?template_uri=%s", url.QueryEscape(template_uri))
+
+	tr, err := transformation.NewTransformation(ctx, tr_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	_, err2 := tr.Transform(ctx, body)
+
+	if err2 == nil {
+		t.Fatalf("Expected template error")
+	}
+
+	if err2.Code != TemplateError {
+		t.Fatalf("Unexpected error code: %d", err2.Code)
+	}
+
+	_, err = transformation.NewTransformation(ctx, "githubtemplate://")
+
+	if err == nil {
+		t.Fatalf("Expected error for missing template URI")
+	}
+
+	_, err = transformation.NewTransformation(ctx, fmt.Sprintf("githubtemplate://?event=bogus&template_uri=%s", url.QueryEscape(template_uri)))
+
+	if err == nil {
+		t.Fatalf("Expected error for invalid ?event= parameter")
+	}
+}
+
+func TestGitHubTemplateTransformationWithInference(t *testing.T) {
+
+	ctx := context.Background()
+
+	template_uri := fmt.Sprintf("constant://?decoder=string&val=%s", url.QueryEscape(`{{ .EventType }} {{ .Event.GetAction }}`))
+	tr_uri := fmt.Sprintf("githubtemplate://?event=infer&template_uri=%s", url.QueryEscape(template_uri))
+
+	tr, err := transformation.NewTransformation(ctx, tr_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, []byte(`{"action":"published","release":{"id":1}}`))
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	if string(rsp) != "release published" {
+		t.Fatalf("Unexpected output: '%s'", string(rsp))
+	}
+
+	_, err2 = tr.Transform(ctx, []byte(`{"pages":[],"forkee":{"id":1}}`))
+
+	if err2 == nil {
+		t.Fatalf("Expected error for ambiguous payload")
+	}
+}
@@ -0,0 +1,182 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/url"
+
+	gogithub "github.com/google/go-github/v48/github"
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubwiki", NewGitHubWikiTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubWikiTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub `gollum`
+// webhook messages in to CSV data containing one row for each wiki page that was created or edited.
+type GitHubWikiTransformation struct {
+	webhookd.WebhookTransformation
+	// ExcludeCreated is a boolean flag to exclude newly created pages from the final output.
+	ExcludeCreated bool
+	// ExcludeEdited is a boolean flag to exclude edited pages from the final output.
+	ExcludeEdited bool
+	// The list of page name patterns to include in the final output. If empty all pages are included.
+	include []string
+	// The list of page name patterns to exclude from the final output.
+	exclude []string
+	// The set of rules used to determine whether the transformer should return an error with code `webhookd.HaltEvent`.
+	rules *RuleSet
+	// The format of the final output. Valid options are `FORMAT_CSV`, `FORMAT_JSON` and `FORMAT_NDJSON`.
+	format string
+	// The list of columns to include in CSV output.
+	columns []string
+}
+
+// NewGitHubWikiTransformation() creates a new `GitHubWikiTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubwiki://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?include` Zero or more `path.Match` patterns for the names of the pages to include in the final output, for example "Data-*". Default is all pages.
+// * `?exclude` Zero or more `path.Match` patterns for the names of the pages to exclude from the final output, for example "_*".
+// * `?exclude_created` An optional boolean value to exclude newly created pages from the final output.
+// * `?exclude_edited` An optional boolean value to exclude edited pages from the final output.
+// * `?columns` An optional comma-separated list of columns to include in CSV output. Valid options are: page, title, action, sha, html_url, repo and summary. Default is page, title, action, sha and html_url.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events where every page is excluded cause the transformer to return an error with code `webhookd.HaltEvent`.
+func NewGitHubWikiTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	p := GitHubWikiTransformation{
+		include: parseListParam(q, "include"),
+		exclude: parseListParam(q, "exclude"),
+		columns: defaultWikiColumns,
+	}
+
+	flags := map[string]*bool{
+		"exclude_created": &p.ExcludeCreated,
+		"exclude_edited":  &p.ExcludeEdited,
+	}
+
+	err = parseBoolParams(q, flags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Has("columns") {
+
+		columns, err := parseWikiColumns(parseListParam(q, "columns"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse ?columns= parameter, %w", err)
+		}
+
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("Failed to parse ?columns= parameter, no columns specified")
+		}
+
+		p.columns = columns
+	}
+
+	format, err := parseFormat(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.format = format
+
+	rules, err := NewRuleSetFromQuery(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = rules.Validate(wikiRuleFieldNames)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid rules, %w", err)
+	}
+
+	p.rules = rules
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `gollum` webhook message) in to CSV data containing one row
+// for each page that was changed: the page name, the page title, the action, the commit hash and the page URL. If 'p' was
+// created with `?format=json` then the output will be a JSON-encoded `WikiRecord`. If 'p' was created with `?format=ndjson`
+// then the output will be one JSON-encoded `WikiPage` per line.
+func (p *GitHubWikiTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+
+	select {
+	case <-ctx.Done():
+		return nil, nil
+	default:
+		// pass
+	}
+
+	event, err := UnmarshalEventAs[gogithub.GollumEvent](body)
+
+	if err != nil {
+		err := &webhookd.WebhookError{Code: 999, Message: err.Error()}
+		return nil, err
+	}
+
+	rec := newWikiRecord(event, wikiPages(event))
+
+	halt_err := p.rules.Evaluate(wikiRuleFields(rec))
+
+	if halt_err != nil {
+		return nil, halt_err
+	}
+
+	rec.Pages = filterWikiPages(rec.Pages, p.include, p.exclude, p.ExcludeCreated, p.ExcludeEdited)
+
+	if len(rec.Pages) == 0 {
+		return nil, &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: "Halt (no pages)"}
+	}
+
+	switch p.format {
+	case FORMAT_JSON:
+		return marshalJSON(rec)
+	case FORMAT_NDJSON:
+		return marshalNDJSON(rec.Pages)
+	default:
+		// pass
+	}
+
+	buf := new(bytes.Buffer)
+	wr := csv.NewWriter(buf)
+
+	for _, pg := range rec.Pages {
+		row := wikiPageRow(pg, p.columns)
+		wr.Write(row)
+	}
+
+	wr.Flush()
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,155 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubWikiTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{
+			"githubwiki://",
+			"Data-Sources,Data Sources,edited,5a9f1c2b7d3e4f6a8b0c1d2e3f4a5b6c7d8e9f01,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/wiki/Data-Sources\nFlight-Codes,Flight Codes,created,c0ffee4b1d2e3f4a5b6c7d8e9f0a1b2c3d4e5f60,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/wiki/Flight-Codes\n_Sidebar,_Sidebar,edited,0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c,https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/wiki/_Sidebar\n",
+		},
+		{
+			"githubwiki://?exclude=_*&columns=repo,page,summary",
+			"sfomuseum-data-flights-2020-05,Data-Sources,Add gate assignment logs\nsfomuseum-data-flights-2020-05,Flight-Codes,\n",
+		},
+		{
+			"githubwiki://?include=Flight-*,Data-*&exclude_created=true&columns=page,action",
+			"Data-Sources,edited\n",
+		},
+		{
+			"githubwiki://?exclude_edited=true&columns=page",
+			"Flight-Codes\n",
+		},
+	}
+
+	ctx := context.Background()
+
+	body := readFixture(t, "fixtures/events/gollum.json")
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform message for %s, %v", test.uri, err2)
+		}
+
+		if string(rsp) != test.expected {
+			t.Fatalf("Unexpected output for %s: '%s'", test.uri, string(rsp))
+		}
+	}
+}
+
+func TestGitHubWikiTransformationWithJSON(t *testing.T) {
+
+	ctx := context.Background()
+
+	body := readFixture(t, "fixtures/events/gollum.json")
+
+	tr, err := transformation.NewTransformation(ctx, "githubwiki://?exclude=_*&format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec WikiRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	if rec.Schema != WIKI_SCHEMA || rec.Actor != "thisisaaronland" || len(rec.Pages) != 2 || rec.Pages[0].Summary != "Add gate assignment logs" {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+
+	tr, err = transformation.NewTransformation(ctx, "githubwiki://?format=ndjson")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 = tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(rsp)), "\n")
+
+	if len(lines) != 3 {
+		t.Fatalf("Unexpected line count: %d", len(lines))
+	}
+
+	var page WikiPage
+
+	err = json.Unmarshal([]byte(lines[1]), &page)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal line, %v", err)
+	}
+
+	if page.Page != "Flight-Codes" || page.Action != WIKI_ACTION_CREATED || page.Repo != "sfomuseum-data-flights-2020-05" {
+		t.Fatalf("Unexpected page: %v", page)
+	}
+}
+
+func TestGitHubWikiTransformationWithHalt(t *testing.T) {
+
+	tests := []string{
+		"githubwiki://?include=Gates",
+		"githubwiki://?exclude=*",
+		"githubwiki://?halt_if=page%3D%3D_Sidebar",
+		"githubwiki://?only_if=actor%3D%3Dsfomuseumbot",
+	}
+
+	ctx := context.Background()
+
+	body := readFixture(t, "fixtures/events/gollum.json")
+
+	for _, uri := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, body)
+
+		if err2 == nil || err2.Code != webhookd.HaltEvent {
+			t.Fatalf("Expected halt event for %s, got %v", uri, err2)
+		}
+	}
+
+	_, err := transformation.NewTransformation(ctx, "githubwiki://?columns=page,path")
+
+	if err == nil {
+		t.Fatalf("Expected invalid ?columns= parameter to fail")
+	}
+}
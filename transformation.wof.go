@@ -0,0 +1,297 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	gogithub "github.com/google/go-github/v48/github"
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubwof", NewGitHubWOFTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// re_wof_filename matches the filenames of Who's On First records and alternate geometry files, for example
+// '1713164509.geojson' or '1713164509-alt-swim-route.geojson'.
+var re_wof_filename = regexp.MustCompile(`^(\d+)(?:-alt-([A-Za-z0-9_\-]+))?\.geojson$`)
+
+// WOFRecord is a single Who's On First record derived from a GitHub `push` event.
+type WOFRecord struct {
+	// ID is the Who's On First ID of the record. For alternate geometry files this is the ID of the parent record.
+	ID int64 `json:"id"`
+	// Alt is the label for alternate geometry files (for example 'swim-route'). It is empty for primary records.
+	Alt string `json:"alt,omitempty"`
+	// Action is the kind of change: `added`, `modified` or `removed`.
+	Action string `json:"action"`
+	// Repo is the name of the repository where the change occurred.
+	Repo string `json:"repo"`
+	// Commit is the hash of the (last) commit in which the record was changed.
+	Commit string `json:"commit"`
+	// Path is the path of the record, relative to the root of the repository.
+	Path string `json:"path"`
+	// FileURLs is the optional set of URLs for the record at 'Commit'.
+	*FileURLs
+}
+
+// parseWOFPath returns the Who's On First ID and alternate geometry label (if present) derived from 'path'. If 'path'
+// is not a Who's On First record the method returns false.
+func parseWOFPath(path string) (int64, string, bool) {
+
+	fname := filepath.Base(path)
+	m := re_wof_filename.FindStringSubmatch(fname)
+
+	if m == nil {
+		return 0, "", false
+	}
+
+	id, err := strconv.ParseInt(m[1], 10, 64)
+
+	if err != nil {
+		return 0, "", false
+	}
+
+	return id, m[2], true
+}
+
+// wofRecords returns the list of unique Who's On First records in 'changes', in the order they were first changed. If a record was changed more
+// than once the last action is used unless a record was added and then modified in which case its action remains `added`.
+func wofRecords(changes []*Change) []*WOFRecord {
+
+	records := make([]*WOFRecord, 0)
+	lookup := make(map[string]*WOFRecord)
+
+	for _, ch := range changes {
+
+		id, alt, ok := parseWOFPath(ch.Path)
+
+		if !ok {
+			continue
+		}
+
+		k := fmt.Sprintf("%d#%s", id, alt)
+
+		rec, exists := lookup[k]
+
+		if !exists {
+
+			rec = &WOFRecord{
+				ID:     id,
+				Alt:    alt,
+				Action: ch.Action,
+				Repo:   ch.Repo,
+				Commit: ch.Commit,
+				Path:   ch.Path,
+			}
+
+			lookup[k] = rec
+			records = append(records, rec)
+			continue
+		}
+
+		if !(rec.Action == ACTION_ADDED && ch.Action == ACTION_MODIFIED) {
+			rec.Action = ch.Action
+		}
+
+		rec.Commit = ch.Commit
+		rec.Path = ch.Path
+	}
+
+	return records
+}
+
+// GitHubWOFTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub
+// commit webhook messages in to CSV data containing: the Who's On First ID, the action, the name of the repository,
+// the commit hash and the alternate geometry label for each Who's On First record that was changed.
+type GitHubWOFTransformation struct {
+	webhookd.WebhookTransformation
+	// ExcludeAdditions is a boolean flag to exclude newly added records from the final output.
+	ExcludeAdditions bool
+	// ExcludeModifications is a boolean flag to exclude updated (modified) records from the final output.
+	ExcludeModifications bool
+	// ExcludeDeletions is a boolean flag to exclude deleted records from the final output.
+	ExcludeDeletions bool
+	// ExcludeAltFiles is a boolean flag to exclude alternate geometry files from the final output.
+	ExcludeAltFiles bool
+	// IncludeURLs is a boolean flag to include the raw, HTML and API URLs for each record in the final output.
+	IncludeURLs bool
+	// The format of the final output. Valid options are `FORMAT_CSV`, `FORMAT_JSON` and `FORMAT_NDJSON`.
+	format string
+	// The set of rules used to determine whether the transformer should return an error with code `webhookd.HaltEvent`.
+	rules *RuleSet
+}
+
+// NewGitHubWOFTransformation() creates a new `GitHubWOFTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubwof://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?exclude_additions` An optional boolean value to exclude newly added records from the final output.
+// * `?exclude_modifications` An optional boolean value to exclude update (modified) records from the final output.
+// * `?exclude_deletions` An optional boolean value to exclude deleted records from the final output.
+// * `?exclude_alt_files` An optional boolean value to exclude alternate geometry files from the final output.
+// * `?include_urls` An optional boolean value to include the raw, HTML and API URLs for each record in the final output. In CSV output these are appended as additional columns.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+func NewGitHubWOFTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	p := GitHubWOFTransformation{}
+
+	flags := map[string]*bool{
+		"exclude_additions":     &p.ExcludeAdditions,
+		"exclude_modifications": &p.ExcludeModifications,
+		"exclude_deletions":     &p.ExcludeDeletions,
+		"exclude_alt_files":     &p.ExcludeAltFiles,
+		"include_urls":          &p.IncludeURLs,
+	}
+
+	err = parseBoolParams(q, flags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := parseFormat(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.format = format
+
+	rules, err := NewRuleSetFromQuery(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = rules.Validate(pushRuleFieldNames)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid rules, %w", err)
+	}
+
+	p.rules = rules
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub commit webhook message) in to CSV data containing: the
+// Who's On First ID, the action, the name of the repository, the commit hash and the alternate geometry label for each unique
+// Who's On First record that was changed. Files that are not Who's On First records are ignored. If 'p' was created with
+// `?format=json` the output will be a JSON-encoded list of `WOFRecord` instances. If 'p' was created with `?format=ndjson`
+// the output will be one JSON-encoded `WOFRecord` per line.
+func (p *GitHubWOFTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+
+	select {
+	case <-ctx.Done():
+		return nil, nil
+	default:
+		// pass
+	}
+
+	var event gogithub.PushEvent
+
+	err := json.Unmarshal(body, &event)
+
+	if err != nil {
+		err := &webhookd.WebhookError{Code: 999, Message: err.Error()}
+		return nil, err
+	}
+
+	changes := pushChanges(&event)
+
+	halt_err := p.rules.Evaluate(pushRuleFields(&event, changes))
+
+	if halt_err != nil {
+		return nil, halt_err
+	}
+
+	records := make([]*WOFRecord, 0)
+
+	for _, rec := range wofRecords(changes) {
+
+		if p.ExcludeAltFiles && rec.Alt != "" {
+			continue
+		}
+
+		switch rec.Action {
+		case ACTION_ADDED:
+			if p.ExcludeAdditions {
+				continue
+			}
+		case ACTION_MODIFIED:
+			if p.ExcludeModifications {
+				continue
+			}
+		case ACTION_REMOVED:
+			if p.ExcludeDeletions {
+				continue
+			}
+		}
+
+		if p.IncludeURLs {
+
+			urls, err := changeFileURLs(&event, &Change{Action: rec.Action, Commit: rec.Commit, Path: rec.Path})
+
+			if err != nil {
+				err := &webhookd.WebhookError{Code: 999, Message: err.Error()}
+				return nil, err
+			}
+
+			rec.FileURLs = urls
+		}
+
+		records = append(records, rec)
+	}
+
+	switch p.format {
+	case FORMAT_JSON:
+		return marshalJSON(records)
+	case FORMAT_NDJSON:
+		return marshalNDJSON(records)
+	default:
+		// pass
+	}
+
+	buf := new(bytes.Buffer)
+	wr := csv.NewWriter(buf)
+
+	for _, rec := range records {
+		row := []string{strconv.FormatInt(rec.ID, 10), rec.Action, rec.Repo, rec.Commit, rec.Alt}
+
+		if rec.FileURLs != nil {
+			row = append(row, rec.RawURL, rec.HTMLURL, rec.APIURL)
+		}
+
+		wr.Write(row)
+	}
+
+	wr.Flush()
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,176 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubWOFTransformation(t *testing.T) {
+
+	expected_rows := 1496
+
+	expected := map[string]string{
+		"1713162531#":              "1713162531,added,sfomuseum-data-flights-2020-05,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,",
+		"1713162531#swim-approach": "1713162531,added,sfomuseum-data-flights-2020-05,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,swim-approach",
+	}
+
+	msg := "fixtures/events/flights.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubwof://")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	csv_r := csv.NewReader(bytes.NewReader(data))
+
+	rows, err := csv_r.ReadAll()
+
+	if err != nil {
+		t.Fatalf("Failed to read CSV data, %v", err)
+	}
+
+	if len(rows) != expected_rows {
+		t.Fatalf("Unexpected row count: %d", len(rows))
+	}
+
+	for _, row := range rows {
+
+		k := row[0] + "#" + row[4]
+		v, ok := expected[k]
+
+		if !ok {
+			continue
+		}
+
+		if strings.Join(row, ",") != v {
+			t.Fatalf("Unexpected row for %s: %s", k, strings.Join(row, ","))
+		}
+
+		delete(expected, k)
+	}
+
+	if len(expected) != 0 {
+		t.Fatalf("Missing expected rows: %v", expected)
+	}
+}
+
+func TestGitHubWOFTransformationWithoutAltFiles(t *testing.T) {
+
+	expected_records := 1211
+
+	msg := "fixtures/events/flights.json"
+	fh, err := os.Open(msg)
+
+	if err != nil {
+		t.Fatalf("Failed to open %s, %v", msg, err)
+	}
+
+	defer fh.Close()
+
+	body, err := io.ReadAll(fh)
+
+	if err != nil {
+		t.Fatalf("Failed to read %s, %v", msg, err)
+	}
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubwof://?exclude_alt_files=true&format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	data, err2 := tr.Transform(ctx, body)
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var records []*WOFRecord
+
+	err = json.Unmarshal(data, &records)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal JSON output, %v", err)
+	}
+
+	if len(records) != expected_records {
+		t.Fatalf("Unexpected record count: %d", len(records))
+	}
+
+	for _, rec := range records {
+
+		if rec.Alt != "" {
+			t.Fatalf("Unexpected alternate geometry file: %s", rec.Path)
+		}
+	}
+}
+
+func TestParseWOFPath(t *testing.T) {
+
+	tests := map[string][]string{
+		"data/171/316/450/9/1713164509.geojson":                {"1713164509", ""},
+		"data/171/316/450/9/1713164509-alt-swim-route.geojson": {"1713164509", "swim-route"},
+	}
+
+	for path, expected := range tests {
+
+		id, alt, ok := parseWOFPath(path)
+
+		if !ok {
+			t.Fatalf("Failed to parse %s", path)
+		}
+
+		if strconv.FormatInt(id, 10) != expected[0] || alt != expected[1] {
+			t.Fatalf("Unexpected values for %s: %d %s", path, id, alt)
+		}
+	}
+
+	invalid := []string{
+		"README.md",
+		"data/171/316/450/9/1713164509.json",
+		"data/171/316/450/9/1713164509-alt-.geojson",
+		"data/sfomuseum.geojson",
+	}
+
+	for _, path := range invalid {
+
+		_, _, ok := parseWOFPath(path)
+
+		if ok {
+			t.Fatalf("Expected %s to be ignored", path)
+		}
+	}
+}
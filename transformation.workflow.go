@@ -0,0 +1,137 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func init() {
+
+	ctx := context.Background()
+	err := transformation.RegisterTransformation(ctx, "githubworkflow", NewGitHubWorkflowTransformation)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GitHubWorkflowTransformation implements the `webhookd.WebhookTransformation` interface for transforming GitHub
+// `workflow_run` and `workflow_job` webhook messages in to GitHub Actions workflow metadata.
+type GitHubWorkflowTransformation struct {
+	webhookd.WebhookTransformation
+	*eventParams
+	// The list of actions to process. If empty all actions are processed.
+	actions []string
+	// The list of workflow name patterns to process. If empty all workflows are processed.
+	workflows []string
+	// The list of workflow path patterns to process. If empty all workflows are processed.
+	paths []string
+	// The list of conclusions to process. If empty all conclusions are processed.
+	conclusions []string
+	// The list of triggering events to process. If empty all triggering events are processed.
+	triggers []string
+	// The list of head branch patterns to process. If empty all branches are processed.
+	branches []string
+}
+
+// workflowEvents defines how `workflow_run` and `workflow_job` events are handled by the `GitHubWorkflowTransformation`.
+var workflowEvents = &eventHandler[*WorkflowRecord]{
+	decoders: map[string]eventDecoder[*WorkflowRecord]{
+		"workflow_run": decodeEventAs(newWorkflowRecordFromRun),
+		"workflow_job": decodeEventWithBody(newWorkflowRecordFromJob),
+	},
+	rule_field_names: workflowRuleFieldNames,
+	rule_fields:      workflowRuleFields,
+	rows:             workflowRows,
+}
+
+// NewGitHubWorkflowTransformation() creates a new `GitHubWorkflowTransformation` instance, configured by 'uri'
+// which is expected to take the form of:
+//
+//	githubworkflow://?{PARAMETERS}
+//
+// Where {PARAMTERS} is:
+// * `?event` The name of the GitHub event that webhook messages are expected to be: "workflow_run", "workflow_job" or "infer" to infer the event type from each message. Default is "workflow_run".
+// * `?min_confidence` The minimum confidence ("low", "medium" or "high") required for an inferred event type to be accepted. Default is "high".
+// * `?action` Zero or more actions to process, for example "requested", "in_progress" or "completed". Default is all actions.
+// * `?workflow` Zero or more `path.Match` patterns for the names of the workflows to process. Default is all workflows.
+// * `?path` Zero or more `path.Match` patterns for the paths of the workflows to process, for example ".github/workflows/validate.yml". Default is all workflows.
+// * `?conclusion` Zero or more conclusions to process, for example "success" or "failure". Default is all conclusions.
+// * `?trigger` Zero or more names of the events that triggered the workflow to process, for example "push" or "schedule". Default is all events.
+// * `?branch` Zero or more `path.Match` patterns for the head branches to process. Default is all branches.
+// * `?halt_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule matches the transformer will return an error with code `webhookd.HaltEvent`
+// * `?only_if` Zero or more rules, in the form of '{FIELD}{OPERATOR}{VALUE}'; if any rule does not match the transformer will return an error with code `webhookd.HaltEvent`
+// * `?format` An optional output format. Valid options are: csv, json, ndjson. Default is csv.
+//
+// Events which are not processed cause the transformer to return an error with code `webhookd.HaltEvent`. Since `workflow_job`
+// messages do not include the path of the workflow or the event that triggered it the `?path` and `?trigger` parameters are
+// only applied to `workflow_run` events.
+func NewGitHubWorkflowTransformation(ctx context.Context, uri string) (webhookd.WebhookTransformation, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	params, err := newEventParams(q, "workflow_run", workflowEvents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := GitHubWorkflowTransformation{
+		eventParams: params,
+		actions:     parseListParam(q, "action"),
+		workflows:   parseListParam(q, "workflow"),
+		paths:       parseListParam(q, "path"),
+		conclusions: parseListParam(q, "conclusion"),
+		triggers:    parseListParam(q, "trigger"),
+		branches:    parseListParam(q, "branch"),
+	}
+
+	return &p, nil
+}
+
+// Transform() transforms 'body' (which is assumed to be a GitHub `workflow_run` or `workflow_job` webhook message) in to
+// CSV data containing: the name of the repository, the name of the workflow, the run ID, the head commit hash, the conclusion,
+// the artifacts URL and the logs URL. If 'p' was created with `?format=json` or `?format=ndjson` the output will be a
+// JSON-encoded `WorkflowRecord`.
+func (p *GitHubWorkflowTransformation) Transform(ctx context.Context, body []byte) ([]byte, *webhookd.WebhookError) {
+	return transformEvent(ctx, p.eventParams, workflowEvents, p.filter, body)
+}
+
+// filter returns a `webhookd.WebhookError` with code `webhookd.HaltEvent` if 'rec' does not match the action, workflow, path,
+// conclusion, trigger and branch filters used to create 'p'. Otherwise it returns nil. The path and trigger filters are only
+// applied to `workflow_run` events since `workflow_job` events have neither.
+func (p *GitHubWorkflowTransformation) filter(rec *WorkflowRecord) *webhookd.WebhookError {
+
+	var msg string
+
+	is_run := rec.Event == "workflow_run"
+
+	switch {
+	case !matchesPattern(rec.Action, p.actions):
+		msg = fmt.Sprintf("Halt (action %s)", rec.Action)
+	case !matchesPattern(rec.Workflow, p.workflows):
+		msg = fmt.Sprintf("Halt (workflow %s)", rec.Workflow)
+	case is_run && !matchesPattern(rec.Path, p.paths):
+		msg = fmt.Sprintf("Halt (path %s)", rec.Path)
+	case !matchesPattern(rec.Conclusion, p.conclusions):
+		msg = fmt.Sprintf("Halt (conclusion %s)", rec.Conclusion)
+	case is_run && !matchesPattern(rec.Trigger, p.triggers):
+		msg = fmt.Sprintf("Halt (trigger %s)", rec.Trigger)
+	case !matchesPattern(rec.Branch, p.branches):
+		msg = fmt.Sprintf("Halt (branch %s)", rec.Branch)
+	default:
+		return nil
+	}
+
+	return &webhookd.WebhookError{Code: webhookd.HaltEvent, Message: msg}
+}
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+	"github.com/whosonfirst/go-webhookd/v3/transformation"
+)
+
+func TestGitHubWorkflowTransformation(t *testing.T) {
+
+	tests := []struct {
+		uri      string
+		msg      string
+		expected string
+	}{
+		{
+			"githubworkflow://",
+			"fixtures/events/workflow_run.json",
+			"sfomuseum-data-flights-2020-05,Validate,5167463810,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,success,https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/actions/runs/5167463810/artifacts,https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/actions/runs/5167463810/logs\n",
+		},
+		{
+			"githubworkflow://?event=workflow_job",
+			"fixtures/events/workflow_job.json",
+			"sfomuseum-data-flights-2020-05,Validate,5167463810,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,failure,https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/actions/runs/5167463810/artifacts,https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/actions/jobs/14003118842/logs\n",
+		},
+		{
+			"githubworkflow://?event=infer",
+			"fixtures/events/workflow_job.json",
+			"sfomuseum-data-flights-2020-05,Validate,5167463810,e3a18d4de60a5e50ca78ca1733238735ddfaef4c,failure,https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/actions/runs/5167463810/artifacts,https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/actions/jobs/14003118842/logs\n",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		body := readFixture(t, test.msg)
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		rsp, err2 := tr.Transform(ctx, body)
+
+		if err2 != nil {
+			t.Fatalf("Failed to transform %s, %v", test.msg, err2)
+		}
+
+		if string(rsp) != test.expected {
+			t.Fatalf("Unexpected output for %s: '%s'", test.msg, string(rsp))
+		}
+	}
+}
+
+func TestGitHubWorkflowTransformationWithJSON(t *testing.T) {
+
+	ctx := context.Background()
+
+	tr, err := transformation.NewTransformation(ctx, "githubworkflow://?event=infer&format=json")
+
+	if err != nil {
+		t.Fatalf("Failed to create new transformation, %v", err)
+	}
+
+	rsp, err2 := tr.Transform(ctx, readFixture(t, "fixtures/events/workflow_run.json"))
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	var rec WorkflowRecord
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	if rec.Schema != WORKFLOW_SCHEMA || rec.Event != "workflow_run" || rec.Path != ".github/workflows/validate.yml" || rec.Trigger != "push" || rec.RunNumber != 118 {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+
+	rsp, err2 = tr.Transform(ctx, readFixture(t, "fixtures/events/workflow_job.json"))
+
+	if err2 != nil {
+		t.Fatalf("Failed to transform message, %v", err2)
+	}
+
+	rec = WorkflowRecord{}
+
+	err = json.Unmarshal(rsp, &rec)
+
+	if err != nil {
+		t.Fatalf("Failed to unmarshal output, %v", err)
+	}
+
+	if rec.Event != "workflow_job" || rec.JobID != 14003118842 || rec.Job != "validate-geojson" || rec.Branch != "main" || rec.RunAttempt != 1 {
+		t.Fatalf("Unexpected record: %v", rec)
+	}
+}
+
+func TestGitHubWorkflowTransformationWithFilters(t *testing.T) {
+
+	run := readFixture(t, "fixtures/events/workflow_run.json")
+	job := readFixture(t, "fixtures/events/workflow_job.json")
+	in_progress := bytes.Replace(run, []byte(`"action": "completed"`), []byte(`"action": "in_progress"`), 1)
+
+	tests := []struct {
+		uri  string
+		body []byte
+		halt bool
+	}{
+		{"githubworkflow://?action=completed&conclusion=success&branch=main&trigger=push&workflow=Validate", run, false},
+		{"githubworkflow://?action=completed", in_progress, true},
+		{"githubworkflow://?conclusion=failure", run, true},
+		{"githubworkflow://?branch=release/*", run, true},
+		{"githubworkflow://?trigger=schedule,workflow_dispatch", run, true},
+		{"githubworkflow://?workflow=Build*", run, true},
+		{"githubworkflow://?path=.github/workflows/validate.yml", run, false},
+		{"githubworkflow://?path=.github/workflows/*.yaml", run, true},
+		{"githubworkflow://?event=workflow_job&conclusion=failure&branch=main", job, false},
+		{"githubworkflow://?event=workflow_job&path=.github/workflows/build.yml", job, false},
+		{"githubworkflow://?event=workflow_job&trigger=schedule", job, false},
+		{"githubworkflow://?only_if=conclusion%3D%3Dsuccess", run, false},
+		{"githubworkflow://?event=workflow_job&halt_if=job%3D~^validate", job, true},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+
+		tr, err := transformation.NewTransformation(ctx, test.uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create new transformation for %s, %v", test.uri, err)
+		}
+
+		_, err2 := tr.Transform(ctx, test.body)
+
+		if test.halt {
+
+			if err2 == nil || err2.Code != webhookd.HaltEvent {
+				t.Fatalf("Expected halt event for %s, got %v", test.uri, err2)
+			}
+
+		} else if err2 != nil {
+			t.Fatalf("Unexpected error for %s, %v", test.uri, err2)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/whosonfirst/go-webhookd/v3"
+)
+
+func TestNewEventParams(t *testing.T) {
+
+	tests := []struct {
+		query string
+		ok    bool
+	}{
+		{"", true},
+		{"event=deployment_status&format=ndjson", true},
+		{"event=infer&min_confidence=medium", true},
+		{"event=push", false},
+		{"format=xml", false},
+		{"halt_if=bogus%3D%3Dvalue", false},
+	}
+
+	for _, test := range tests {
+
+		q, err := url.ParseQuery(test.query)
+
+		if err != nil {
+			t.Fatalf("Failed to parse query '%s', %v", test.query, err)
+		}
+
+		_, err = newEventParams(q, "deployment", deploymentEvents)
+
+		if test.ok && err != nil {
+			t.Fatalf("Unexpected error for '%s', %v", test.query, err)
+		}
+
+		if !test.ok && err == nil {
+			t.Fatalf("Expected error for '%s'", test.query)
+		}
+	}
+}
+
+func TestTransformEvent(t *testing.T) {
+
+	ctx := context.Background()
+
+	no_filter := func(rec *DeploymentRecord) *webhookd.WebhookError {
+		return nil
+	}
+
+	tests := []struct {
+		query string
+		msg   string
+		code  int
+	}{
+		{"event=infer", "fixtures/events/push.json", webhookd.UnhandledEvent},
+		{"event=infer", "fixtures/events/deployment_status.json", 0},
+		{"format=ndjson", "fixtures/events/deployment.json", 0},
+		{"only_if=environment%3D%3Dstaging", "fixtures/events/deployment.json", webhookd.HaltEvent},
+	}
+
+	for _, test := range tests {
+
+		q, err := url.ParseQuery(test.query)
+
+		if err != nil {
+			t.Fatalf("Failed to parse query '%s', %v", test.query, err)
+		}
+
+		p, err := newEventParams(q, "deployment", deploymentEvents)
+
+		if err != nil {
+			t.Fatalf("Failed to create event params for '%s', %v", test.query, err)
+		}
+
+		rsp, err2 := transformEvent(ctx, p, deploymentEvents, no_filter, readFixture(t, test.msg))
+
+		if test.code == 0 {
+
+			if err2 != nil {
+				t.Fatalf("Unexpected error for '%s' (%s), %v", test.query, test.msg, err2)
+			}
+
+			if len(rsp) == 0 {
+				t.Fatalf("Expected output for '%s' (%s)", test.query, test.msg)
+			}
+
+			continue
+		}
+
+		if err2 == nil || err2.Code != test.code {
+			t.Fatalf("Expected error code %d for '%s' (%s), got %v", test.code, test.query, test.msg, err2)
+		}
+	}
+
+	p, err := newEventParams(url.Values{}, "deployment", deploymentEvents)
+
+	if err != nil {
+		t.Fatalf("Failed to create event params, %v", err)
+	}
+
+	_, err2 := transformEvent(ctx, p, deploymentEvents, no_filter, []byte(`{"deployment": []}`))
+
+	if err2 == nil || err2.Code != 999 {
+		t.Fatalf("Expected unmarshal error, got %v", err2)
+	}
+}
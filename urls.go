@@ -0,0 +1,117 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// GITHUB_HOST is the hostname for (public) GitHub repositories.
+const GITHUB_HOST string = "github.com"
+
+// GITHUB_RAW_HOST is the hostname used to serve raw file contents for (public) GitHub repositories.
+const GITHUB_RAW_HOST string = "raw.githubusercontent.com"
+
+// FileURLs is the set of URLs for a file at a specific commit.
+type FileURLs struct {
+	// RawURL is the URL for the raw contents of the file.
+	RawURL string `json:"raw_url"`
+	// HTMLURL is the URL for the (HTML) blob view of the file.
+	HTMLURL string `json:"html_url"`
+	// APIURL is the URL for the GitHub contents API for the file.
+	APIURL string `json:"api_url"`
+}
+
+// escapePath returns 'path' with each of its segments URL-escaped.
+func escapePath(path string) string {
+
+	parts := strings.Split(path, "/")
+
+	for idx, p := range parts {
+		parts[idx] = url.PathEscape(p)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// repoAPIURL returns the GitHub API URL for 'repo' derived from its `statuses_url` or `archive_url` properties, both of
+// which are URI templates rooted in the API URL for the repository.
+func repoAPIURL(repo *gogithub.PushEventRepository) (string, error) {
+
+	candidates := [][]string{
+		{repo.GetStatusesURL(), "/statuses/"},
+		{repo.GetArchiveURL(), "/{archive_format}"},
+	}
+
+	for _, c := range candidates {
+
+		tmpl := c[0]
+		idx := strings.LastIndex(tmpl, c[1])
+
+		if idx == -1 {
+			continue
+		}
+
+		return tmpl[0:idx], nil
+	}
+
+	return "", fmt.Errorf("Unable to derive API URL for repository '%s'", repo.GetFullName())
+}
+
+// newFileURLs returns a new `FileURLs` instance for 'path' at commit 'sha' in 'repo'. URLs are derived from the URLs in the
+// repository payload so that they work for both GitHub and GitHub Enterprise hosts.
+func newFileURLs(repo *gogithub.PushEventRepository, sha string, path string) (*FileURLs, error) {
+
+	html_url := repo.GetHTMLURL()
+
+	if html_url == "" {
+		html_url = repo.GetURL()
+	}
+
+	html_u, err := url.Parse(html_url)
+
+	if err != nil || html_u.Host == "" {
+		return nil, fmt.Errorf("Unable to derive HTML URL for repository '%s'", repo.GetFullName())
+	}
+
+	api_url, err := repoAPIURL(repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	html_url = strings.TrimSuffix(html_url, "/")
+	escaped_sha := url.PathEscape(sha)
+	escaped_path := escapePath(path)
+
+	var raw_url string
+
+	if html_u.Host == GITHUB_HOST {
+		raw_url = fmt.Sprintf("https://%s%s/%s/%s", GITHUB_RAW_HOST, strings.TrimSuffix(html_u.Path, "/"), escaped_sha, escaped_path)
+	} else {
+		raw_url = fmt.Sprintf("%s/raw/%s/%s", html_url, escaped_sha, escaped_path)
+	}
+
+	urls := &FileURLs{
+		RawURL:  raw_url,
+		HTMLURL: fmt.Sprintf("%s/blob/%s/%s", html_url, escaped_sha, escaped_path),
+		APIURL:  fmt.Sprintf("%s/contents/%s?ref=%s", api_url, escaped_path, url.QueryEscape(sha)),
+	}
+
+	return urls, nil
+}
+
+// changeFileURLs returns a new `FileURLs` instance for 'ch', a change that occurred in 'event'. URLs for files that
+// were removed point to the state of the repository before the push.
+func changeFileURLs(event *gogithub.PushEvent, ch *Change) (*FileURLs, error) {
+
+	sha := ch.Commit
+
+	if ch.Action == ACTION_REMOVED {
+		sha = event.GetBefore()
+	}
+
+	return newFileURLs(event.GetRepo(), sha, ch.Path)
+}
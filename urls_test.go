@@ -0,0 +1,57 @@
+package github
+
+import (
+	"testing"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+func TestNewFileURLs(t *testing.T) {
+
+	sha := "e3a18d4de60a5e50ca78ca1733238735ddfaef4c"
+	path := "data/sfo/Terminal 2 #1?.geojson"
+
+	public := &gogithub.PushEventRepository{
+		FullName:    gogithub.String("sfomuseum-data/sfomuseum-data-flights-2020-05"),
+		HTMLURL:     gogithub.String("https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05"),
+		StatusesURL: gogithub.String("https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/statuses/{sha}"),
+	}
+
+	enterprise := &gogithub.PushEventRepository{
+		FullName:   gogithub.String("data/flights"),
+		HTMLURL:    gogithub.String("https://git.example.com/data/flights"),
+		ArchiveURL: gogithub.String("https://git.example.com/api/v3/repos/data/flights/{archive_format}{/ref}"),
+	}
+
+	tests := map[*gogithub.PushEventRepository]*FileURLs{
+		public: {
+			RawURL:  "https://raw.githubusercontent.com/sfomuseum-data/sfomuseum-data-flights-2020-05/e3a18d4de60a5e50ca78ca1733238735ddfaef4c/data/sfo/Terminal%202%20%231%3F.geojson",
+			HTMLURL: "https://github.com/sfomuseum-data/sfomuseum-data-flights-2020-05/blob/e3a18d4de60a5e50ca78ca1733238735ddfaef4c/data/sfo/Terminal%202%20%231%3F.geojson",
+			APIURL:  "https://api.github.com/repos/sfomuseum-data/sfomuseum-data-flights-2020-05/contents/data/sfo/Terminal%202%20%231%3F.geojson?ref=e3a18d4de60a5e50ca78ca1733238735ddfaef4c",
+		},
+		enterprise: {
+			RawURL:  "https://git.example.com/data/flights/raw/e3a18d4de60a5e50ca78ca1733238735ddfaef4c/data/sfo/Terminal%202%20%231%3F.geojson",
+			HTMLURL: "https://git.example.com/data/flights/blob/e3a18d4de60a5e50ca78ca1733238735ddfaef4c/data/sfo/Terminal%202%20%231%3F.geojson",
+			APIURL:  "https://git.example.com/api/v3/repos/data/flights/contents/data/sfo/Terminal%202%20%231%3F.geojson?ref=e3a18d4de60a5e50ca78ca1733238735ddfaef4c",
+		},
+	}
+
+	for repo, expected := range tests {
+
+		urls, err := newFileURLs(repo, sha, path)
+
+		if err != nil {
+			t.Fatalf("Failed to derive URLs for %s, %v", repo.GetFullName(), err)
+		}
+
+		if *urls != *expected {
+			t.Fatalf("Unexpected URLs for %s: %v", repo.GetFullName(), urls)
+		}
+	}
+
+	_, err := newFileURLs(&gogithub.PushEventRepository{}, sha, path)
+
+	if err == nil {
+		t.Fatalf("Expected repository without URLs to fail")
+	}
+}
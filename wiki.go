@@ -0,0 +1,227 @@
+package github
+
+import (
+	"fmt"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// WIKI_SCHEMA is the name of the schema used to encode `gollum` events as JSON.
+const WIKI_SCHEMA string = "wiki"
+
+// WIKI_SCHEMA_VERSION is the current version of the `WIKI_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const WIKI_SCHEMA_VERSION int = 1
+
+// WIKI_ACTION_CREATED is the action for wiki pages which were created.
+const WIKI_ACTION_CREATED string = "created"
+
+// WIKI_ACTION_EDITED is the action for wiki pages which were edited.
+const WIKI_ACTION_EDITED string = "edited"
+
+// COLUMN_PAGE is the CSV column for the name of a wiki page.
+const COLUMN_PAGE string = "page"
+
+// COLUMN_TITLE is the CSV column for the title of a wiki page.
+const COLUMN_TITLE string = "title"
+
+// COLUMN_SHA is the CSV column for the hash of the latest commit to a wiki page.
+const COLUMN_SHA string = "sha"
+
+// COLUMN_SUMMARY is the CSV column for the summary of the change to a wiki page.
+const COLUMN_SUMMARY string = "summary"
+
+// defaultWikiColumns is the default list of CSV columns output by wiki transformations.
+var defaultWikiColumns = []string{
+	COLUMN_PAGE,
+	COLUMN_TITLE,
+	COLUMN_ACTION,
+	COLUMN_SHA,
+	COLUMN_HTML_URL,
+}
+
+// WikiPage is a single wiki page changed by a GitHub `gollum` event.
+type WikiPage struct {
+	// Repo is the name of the repository the wiki belongs to.
+	Repo string `json:"repo"`
+	// Page is the name of the page, for example "Data-Sources".
+	Page string `json:"page"`
+	// Title is the title of the page.
+	Title string `json:"title"`
+	// Action is the change made to the page: `WIKI_ACTION_CREATED` or `WIKI_ACTION_EDITED`.
+	Action string `json:"action"`
+	// SHA is the hash of the latest commit to the page.
+	SHA string `json:"sha"`
+	// HTMLURL is the URL of the (HTML) web page for the page.
+	HTMLURL string `json:"html_url"`
+	// Summary is the (optional) summary of the change to the page.
+	Summary string `json:"summary,omitempty"`
+}
+
+// WikiRecord is the JSON-encoded representation of a GitHub `gollum` event produced by transformations in this package.
+type WikiRecord struct {
+	// Schema is the name of the schema for the record. It is always `WIKI_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event. It is always "gollum".
+	Event string `json:"event"`
+	// Repo is the name of the repository the wiki belongs to.
+	Repo string `json:"repo"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository the wiki belongs to.
+	FullName string `json:"full_name"`
+	// Actor is the login of the user who changed the pages.
+	Actor string `json:"actor"`
+	// Pages is the list of pages which were changed.
+	Pages []*WikiPage `json:"pages"`
+}
+
+// wikiPages returns the list of `WikiPage` instances for the pages changed by 'event'.
+func wikiPages(event *gogithub.GollumEvent) []*WikiPage {
+
+	repo := event.GetRepo().GetName()
+	pages := make([]*WikiPage, len(event.Pages))
+
+	for idx, p := range event.Pages {
+
+		pages[idx] = &WikiPage{
+			Repo:    repo,
+			Page:    p.GetPageName(),
+			Title:   p.GetTitle(),
+			Action:  p.GetAction(),
+			SHA:     p.GetSHA(),
+			HTMLURL: p.GetHTMLURL(),
+			Summary: p.GetSummary(),
+		}
+	}
+
+	return pages
+}
+
+// newWikiRecord returns a new `WikiRecord` instance derived from 'event' and 'pages'.
+func newWikiRecord(event *gogithub.GollumEvent, pages []*WikiPage) *WikiRecord {
+
+	repo := event.GetRepo()
+
+	rec := &WikiRecord{
+		Schema:   WIKI_SCHEMA,
+		Version:  WIKI_SCHEMA_VERSION,
+		Event:    "gollum",
+		Repo:     repo.GetName(),
+		FullName: repo.GetFullName(),
+		Actor:    event.GetSender().GetLogin(),
+		Pages:    pages,
+	}
+
+	return rec
+}
+
+// filterWikiPages returns the subset of 'pages' whose names match any of the patterns in 'include' (or all pages if 'include'
+// is empty) and none of the patterns in 'exclude', excluding created or edited pages if 'exclude_created' or 'exclude_edited'
+// are true. Names are matched using the same rules as file paths in the commit transformations (see `matchesFilters`).
+func filterWikiPages(pages []*WikiPage, include []string, exclude []string, exclude_created bool, exclude_edited bool) []*WikiPage {
+
+	exclude_actions := map[string]bool{
+		WIKI_ACTION_CREATED: exclude_created,
+		WIKI_ACTION_EDITED:  exclude_edited,
+	}
+
+	filtered := make([]*WikiPage, 0)
+
+	for _, p := range filterActions(pages, func(p *WikiPage) string { return p.Action }, exclude_actions) {
+
+		if matchesFilters(p.Page, include, exclude) {
+			filtered = append(filtered, p)
+		}
+	}
+
+	return filtered
+}
+
+// parseWikiColumns returns the list of valid wiki CSV column names in 'names'.
+func parseWikiColumns(names []string) ([]string, error) {
+
+	columns := make([]string, 0)
+
+	for _, n := range names {
+
+		switch n {
+		case COLUMN_PAGE, COLUMN_TITLE, COLUMN_ACTION, COLUMN_SHA, COLUMN_HTML_URL, COLUMN_REPO, COLUMN_SUMMARY:
+			// pass
+		default:
+			return nil, fmt.Errorf("Invalid column '%s'", n)
+		}
+
+		columns = append(columns, n)
+	}
+
+	return columns, nil
+}
+
+// wikiPageRow returns the values of 'columns' for 'p'.
+func wikiPageRow(p *WikiPage, columns []string) []string {
+
+	row := make([]string, len(columns))
+
+	for idx, col := range columns {
+
+		switch col {
+		case COLUMN_PAGE:
+			row[idx] = p.Page
+		case COLUMN_TITLE:
+			row[idx] = p.Title
+		case COLUMN_ACTION:
+			row[idx] = p.Action
+		case COLUMN_SHA:
+			row[idx] = p.SHA
+		case COLUMN_HTML_URL:
+			row[idx] = p.HTMLURL
+		case COLUMN_REPO:
+			row[idx] = p.Repo
+		case COLUMN_SUMMARY:
+			row[idx] = p.Summary
+		}
+	}
+
+	return row
+}
+
+// wikiRuleFieldNames is the list of field names that rules for wiki events may be evaluated against.
+var wikiRuleFieldNames = []string{
+	"repo",
+	"full_name",
+	"actor",
+	"page",
+	"title",
+	"action",
+	"summary",
+}
+
+// wikiRuleFields returns the `RuleFields` for 'rec' used to evaluate rules. The "page", "title", "action" and "summary"
+// fields contain one value for each changed page.
+func wikiRuleFields(rec *WikiRecord) RuleFields {
+
+	pages := make([]string, len(rec.Pages))
+	titles := make([]string, len(rec.Pages))
+	actions := make([]string, len(rec.Pages))
+	summaries := make([]string, len(rec.Pages))
+
+	for idx, p := range rec.Pages {
+		pages[idx] = p.Page
+		titles[idx] = p.Title
+		actions[idx] = p.Action
+		summaries[idx] = p.Summary
+	}
+
+	fields := RuleFields{
+		"repo":      []string{rec.Repo},
+		"full_name": []string{rec.FullName},
+		"actor":     []string{rec.Actor},
+		"page":      pages,
+		"title":     titles,
+		"action":    actions,
+		"summary":   summaries,
+	}
+
+	return fields
+}
@@ -0,0 +1,203 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	gogithub "github.com/google/go-github/v48/github"
+)
+
+// WORKFLOW_SCHEMA is the name of the schema used to encode `workflow_run` and `workflow_job` events as JSON.
+const WORKFLOW_SCHEMA string = "workflow"
+
+// WORKFLOW_SCHEMA_VERSION is the current version of the `WORKFLOW_SCHEMA` schema. It will be incremented
+// if and when existing properties are removed or change meaning.
+const WORKFLOW_SCHEMA_VERSION int = 1
+
+// WorkflowRecord is the JSON-encoded representation of a GitHub `workflow_run` or `workflow_job` event produced by transformations in this package.
+type WorkflowRecord struct {
+	// Schema is the name of the schema for the record. It is always `WORKFLOW_SCHEMA`.
+	Schema string `json:"schema"`
+	// Version is the version of the schema for the record.
+	Version int `json:"version"`
+	// Event is the name of the GitHub event: "workflow_run" or "workflow_job".
+	Event string `json:"event"`
+	// Action is the activity that triggered the event, for example "requested", "in_progress" or "completed".
+	Action string `json:"action"`
+	// Repo is the name of the repository where the workflow ran.
+	Repo string `json:"repo"`
+	// FullName is the full name ({OWNER}/{REPO}) of the repository where the workflow ran.
+	FullName string `json:"full_name"`
+	// Workflow is the name of the workflow.
+	Workflow string `json:"workflow"`
+	// Path is the path of the workflow file, for example ".github/workflows/validate.yml". It is empty for `workflow_job` events.
+	Path string `json:"path,omitempty"`
+	// Trigger is the name of the event that triggered the workflow, for example "push". It is empty for `workflow_job` events.
+	Trigger string `json:"trigger,omitempty"`
+	// RunID is the unique ID of the workflow run.
+	RunID int64 `json:"run_id"`
+	// RunNumber is the repository-specific number of the workflow run. It is zero for `workflow_job` events.
+	RunNumber int `json:"run_number,omitempty"`
+	// RunAttempt is the attempt number of the workflow run.
+	RunAttempt int `json:"run_attempt,omitempty"`
+	// JobID is the unique ID of the workflow job. It is zero for `workflow_run` events.
+	JobID int64 `json:"job_id,omitempty"`
+	// Job is the name of the workflow job. It is empty for `workflow_run` events.
+	Job string `json:"job,omitempty"`
+	// Branch is the name of the head branch the workflow ran against.
+	Branch string `json:"branch"`
+	// SHA is the hash of the head commit the workflow ran against.
+	SHA string `json:"sha"`
+	// Status is the status of the workflow run or job, for example "queued" or "completed".
+	Status string `json:"status"`
+	// Conclusion is the result of a completed workflow run or job, for example "success" or "failure".
+	Conclusion string `json:"conclusion,omitempty"`
+	// HTMLURL is the URL of the (HTML) web page for the workflow run or job.
+	HTMLURL string `json:"html_url"`
+	// ArtifactsURL is the GitHub API URL for the artifacts produced by the workflow run.
+	ArtifactsURL string `json:"artifacts_url"`
+	// LogsURL is the GitHub API URL for the logs of the workflow run or job.
+	LogsURL string `json:"logs_url"`
+}
+
+// workflowJobExtras contains properties of `workflow_job` webhook messages which are not (yet) defined by the go-github
+// `WorkflowJob` type.
+type workflowJobExtras struct {
+	WorkflowJob struct {
+		WorkflowName string `json:"workflow_name"`
+		HeadBranch   string `json:"head_branch"`
+		RunAttempt   int    `json:"run_attempt"`
+	} `json:"workflow_job"`
+}
+
+// newWorkflowRecordFromRun returns a new `WorkflowRecord` instance derived from 'event'.
+func newWorkflowRecordFromRun(event *gogithub.WorkflowRunEvent) *WorkflowRecord {
+
+	run := event.GetWorkflowRun()
+	repo := event.GetRepo()
+
+	rec := &WorkflowRecord{
+		Schema:       WORKFLOW_SCHEMA,
+		Version:      WORKFLOW_SCHEMA_VERSION,
+		Event:        "workflow_run",
+		Action:       event.GetAction(),
+		Repo:         repo.GetName(),
+		FullName:     repo.GetFullName(),
+		Workflow:     event.GetWorkflow().GetName(),
+		Path:         event.GetWorkflow().GetPath(),
+		Trigger:      run.GetEvent(),
+		RunID:        run.GetID(),
+		RunNumber:    run.GetRunNumber(),
+		RunAttempt:   run.GetRunAttempt(),
+		Branch:       run.GetHeadBranch(),
+		SHA:          run.GetHeadSHA(),
+		Status:       run.GetStatus(),
+		Conclusion:   run.GetConclusion(),
+		HTMLURL:      run.GetHTMLURL(),
+		ArtifactsURL: run.GetArtifactsURL(),
+		LogsURL:      run.GetLogsURL(),
+	}
+
+	if rec.Workflow == "" {
+		rec.Workflow = run.GetName()
+	}
+
+	return rec
+}
+
+// newWorkflowRecordFromJob returns a new `WorkflowRecord` instance derived from 'event' and 'body', the webhook message
+// 'event' was unmarshaled from.
+func newWorkflowRecordFromJob(event *gogithub.WorkflowJobEvent, body []byte) (*WorkflowRecord, error) {
+
+	var extras workflowJobExtras
+
+	err := json.Unmarshal(body, &extras)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal workflow job, %w", err)
+	}
+
+	job := event.GetWorkflowJob()
+	repo := event.GetRepo()
+
+	rec := &WorkflowRecord{
+		Schema:     WORKFLOW_SCHEMA,
+		Version:    WORKFLOW_SCHEMA_VERSION,
+		Event:      "workflow_job",
+		Action:     event.GetAction(),
+		Repo:       repo.GetName(),
+		FullName:   repo.GetFullName(),
+		Workflow:   extras.WorkflowJob.WorkflowName,
+		RunID:      job.GetRunID(),
+		RunAttempt: extras.WorkflowJob.RunAttempt,
+		JobID:      job.GetID(),
+		Job:        job.GetName(),
+		Branch:     extras.WorkflowJob.HeadBranch,
+		SHA:        job.GetHeadSHA(),
+		Status:     job.GetStatus(),
+		Conclusion: job.GetConclusion(),
+		HTMLURL:    job.GetHTMLURL(),
+	}
+
+	// Workflow job messages don't include artifacts or logs URLs but both can be derived
+	// from the (API) URLs for the run and the job
+
+	if job.GetRunURL() != "" {
+		rec.ArtifactsURL = strings.TrimSuffix(job.GetRunURL(), "/") + "/artifacts"
+	}
+
+	if job.GetURL() != "" {
+		rec.LogsURL = strings.TrimSuffix(job.GetURL(), "/") + "/logs"
+	}
+
+	return rec, nil
+}
+
+// workflowRuleFieldNames is the list of field names that rules for workflow events may be evaluated against.
+var workflowRuleFieldNames = []string{
+	"event",
+	"action",
+	"repo",
+	"full_name",
+	"workflow",
+	"path",
+	"trigger",
+	"job",
+	"branch",
+	"sha",
+	"status",
+	"conclusion",
+	"run_attempt",
+}
+
+// workflowRuleFields returns the `RuleFields` for 'rec' used to evaluate rules.
+func workflowRuleFields(rec *WorkflowRecord) RuleFields {
+
+	fields := RuleFields{
+		"event":       []string{rec.Event},
+		"action":      []string{rec.Action},
+		"repo":        []string{rec.Repo},
+		"full_name":   []string{rec.FullName},
+		"workflow":    []string{rec.Workflow},
+		"path":        []string{rec.Path},
+		"trigger":     []string{rec.Trigger},
+		"job":         []string{rec.Job},
+		"branch":      []string{rec.Branch},
+		"sha":         []string{rec.SHA},
+		"status":      []string{rec.Status},
+		"conclusion":  []string{rec.Conclusion},
+		"run_attempt": []string{strconv.Itoa(rec.RunAttempt)},
+	}
+
+	return fields
+}
+
+// workflowRows returns the CSV rows for 'rec': the name of the repository, the name of the workflow, the run ID, the commit hash,
+// the conclusion, the artifacts URL and the logs URL.
+func workflowRows(rec *WorkflowRecord) [][]string {
+
+	row := []string{rec.Repo, rec.Workflow, strconv.FormatInt(rec.RunID, 10), rec.SHA, rec.Conclusion, rec.ArtifactsURL, rec.LogsURL}
+	return [][]string{row}
+}